@@ -0,0 +1,98 @@
+package cellabi
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// BindHostFunc registers fn — an ordinary Go func whose parameters and
+// return values may use float64, []byte, string or small structs in
+// addition to the usual integer/float32 types — as a wasm host function
+// named export on builder. The first parameter of fn may optionally be a
+// context.Context, matching wazero's own convention.
+//
+// cellabi reflects over fn's signature once at registration time, builds
+// a shim per parameter/result, and does the linear-memory marshaling on
+// every call so callers never hand-write i32/i64/f32/f64 plumbing.
+//
+// Results are encoded without an Allocator, so a []byte or string result
+// (or a struct result with such a field) registers without error but
+// panics with a cellabi error when the function is called.
+func BindHostFunc(builder wazero.HostModuleBuilder, export string, fn interface{}) error {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		return fmt.Errorf("cellabi: BindHostFunc(%s): not a function", export)
+	}
+
+	paramStart := 0
+	wantsCtx := ft.NumIn() > 0 && ft.In(0) == reflect.TypeOf((*context.Context)(nil)).Elem()
+	if wantsCtx {
+		paramStart = 1
+	}
+
+	paramShims := make([]shim, ft.NumIn()-paramStart)
+	for i := range paramShims {
+		s, err := shimFor(ft.In(i + paramStart))
+		if err != nil {
+			return fmt.Errorf("cellabi: BindHostFunc(%s): param %d: %w", export, i, err)
+		}
+		paramShims[i] = s
+	}
+	resultShims := make([]shim, ft.NumOut())
+	for i := range resultShims {
+		s, err := shimFor(ft.Out(i))
+		if err != nil {
+			return fmt.Errorf("cellabi: BindHostFunc(%s): result %d: %w", export, i, err)
+		}
+		resultShims[i] = s
+	}
+
+	builder.NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			args := make([]reflect.Value, ft.NumIn())
+			if wantsCtx {
+				args[0] = reflect.ValueOf(ctx)
+			}
+			lane := 0
+			for i, s := range paramShims {
+				v, err := s.decode(mod.Memory(), stack[lane:lane+s.lanes], ft.In(i+paramStart))
+				if err != nil {
+					panic(fmt.Errorf("cellabi: decoding param %d of %s: %w", i, export, err))
+				}
+				args[i+paramStart] = v
+				lane += s.lanes
+			}
+
+			out := fv.Call(args)
+
+			lane = 0
+			for i, s := range resultShims {
+				enc, err := s.encode(mod.Memory(), nil, out[i])
+				if err != nil {
+					panic(fmt.Errorf("cellabi: encoding result %d of %s: %w", i, export, err))
+				}
+				copy(stack[lane:lane+s.lanes], enc)
+				lane += s.lanes
+			}
+		}), laneValueTypes(paramShims), laneValueTypes(resultShims)).
+		Export(export)
+
+	return nil
+}
+
+// laneValueTypes reports the flat wasm api.ValueType sequence a set of
+// shims occupies, taken from each shim's own laneTypes — i32 for 32-bit
+// scalars and for each i32 of a pointer+len pair, i64/f32/f64 only for
+// types that are genuinely that width on the wasm side.
+func laneValueTypes(shims []shim) []api.ValueType {
+	var types []api.ValueType
+	for _, s := range shims {
+		types = append(types, s.laneTypes...)
+	}
+	return types
+}
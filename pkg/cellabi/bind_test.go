@@ -0,0 +1,101 @@
+package cellabi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// uleb128 encodes n as unsigned LEB128, the variable-length integer format
+// every size/count field in the wasm binary format uses.
+func uleb128(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+func wasmName(s string) []byte {
+	return append(uleb128(len(s)), []byte(s)...)
+}
+
+func wasmVec(items ...[]byte) []byte {
+	out := uleb128(len(items))
+	for _, it := range items {
+		out = append(out, it...)
+	}
+	return out
+}
+
+func wasmSection(id byte, content []byte) []byte {
+	out := []byte{id}
+	out = append(out, uleb128(len(content))...)
+	return append(out, content...)
+}
+
+const (
+	wasmI32 = 0x7f
+)
+
+// buildImportingModule hand-assembles (no TinyGo/wat2wasm needed) the
+// smallest possible wasm module that imports moduleName.funcName with the
+// given param/result types and otherwise does nothing — enough to make
+// wazero's InstantiateModule enforce the import's function signature
+// against whatever BindHostFunc registered it as.
+func buildImportingModule(moduleName, funcName string, params, results []byte) []byte {
+	functype := []byte{0x60}
+	functype = append(functype, uleb128(len(params))...)
+	functype = append(functype, params...)
+	functype = append(functype, uleb128(len(results))...)
+	functype = append(functype, results...)
+
+	typeSec := wasmSection(1, wasmVec(functype))
+
+	imp := append(wasmName(moduleName), wasmName(funcName)...)
+	imp = append(imp, 0x00)          // import kind: func
+	imp = append(imp, uleb128(0)...) // type index 0
+	importSec := wasmSection(2, wasmVec(imp))
+
+	out := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00} // \0asm, version 1
+	out = append(out, typeSec...)
+	out = append(out, importSec...)
+	return out
+}
+
+// TestBindHostFuncMatchesCodegenImportSignature instantiates a real wasm
+// module whose import declares the exact (i32)->i32 signature cellabigen's
+// GenerateGuest emits for a uint32-in/uint32-out method (see
+// pkg/cellabi/cellabigen/generate_test.go's ActuateFan case), and checks it
+// links against a host function registered via BindHostFunc for the same
+// Go signature. When laneValueTypes reported every lane as i64, this
+// failed with a wazero signature-mismatch error even though the shim-level
+// encode/decode round trip (FuzzUint32Float32RoundTrip-style tests) passed.
+func TestBindHostFuncMatchesCodegenImportSignature(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	builder := runtime.NewHostModuleBuilder("env")
+	if err := BindHostFunc(builder, "ActuateFan", func(ctx context.Context, power uint32) uint32 {
+		return 1
+	}); err != nil {
+		t.Fatalf("BindHostFunc: %v", err)
+	}
+	if _, err := builder.Instantiate(ctx); err != nil {
+		t.Fatalf("instantiating host module: %v", err)
+	}
+
+	wasmBytes := buildImportingModule("env", "ActuateFan", []byte{wasmI32}, []byte{wasmI32})
+	if _, err := runtime.Instantiate(ctx, wasmBytes); err != nil {
+		t.Fatalf("instantiating guest importing ActuateFan(uint32) uint32: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package cellabi is a reflective binding layer between Go's native types
+// and the flat i32/i64/f32/f64 calling convention wasm guests and hosts
+// actually speak. Firmware authors declare host imports and guest exports
+// as ordinary Go functions (float64, []byte, string, small structs
+// included) and cellabi generates the marshaling shim and linear-memory
+// read/write glue at module-instantiate time, so the sensor/actuator
+// contract only needs to be written once.
+package cellabi
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Memory is the subset of api.Module that marshaling needs to read and
+// write the guest's linear memory for []byte/string/struct parameters.
+type Memory interface {
+	Read(offset, byteCount uint32) ([]byte, bool)
+	Write(offset uint32, v []byte) bool
+}
+
+// shim turns a single Go value (argument or return) into/from wasm's flat
+// uint64 lane representation. lanes reports how many wasm lanes the value
+// consumes, which is two i32 lanes (ptr, len) for []byte and string.
+type shim struct {
+	// encode converts v (already of the Go kind this shim handles) into
+	// its wasm lanes, staging the bytes in guest memory via alloc and mem
+	// when the value does not fit in a lane directly.
+	encode func(mem Memory, alloc Allocator, v reflect.Value) ([]uint64, error)
+	// decode reads the wasm lanes back into a reflect.Value of the target
+	// Go type.
+	decode func(mem Memory, lanes []uint64, target reflect.Type) (reflect.Value, error)
+	// lanes is how many uint64 wasm lanes this shim occupies.
+	lanes int
+	// laneTypes is the wasm api.ValueType of each of this shim's lanes, in
+	// order. wazero's stack representation is always a uint64 per lane
+	// regardless of the declared type, but the *function signature*
+	// registered on the HostModuleBuilder must match the width the guest's
+	// compiler actually emits — TinyGo (and wasm generally, which has no
+	// native sub-32-bit int type) uses i32 for everything up to and
+	// including uint32/int32, and i64 only for genuinely 64-bit values.
+	// Getting this wrong fails InstantiateModule with a signature mismatch
+	// rather than anything a unit test exercising encode/decode alone would
+	// catch.
+	laneTypes []api.ValueType
+}
+
+// Allocator reserves space in the guest's linear memory, e.g. by calling a
+// guest-exported allocator function. Host-to-guest calls that pass []byte
+// or string arguments need one to stage the bytes before the call.
+type Allocator func(size uint32) (ptr uint32, err error)
+
+func shimFor(t reflect.Type) (shim, error) {
+	switch t.Kind() {
+	case reflect.Uint32, reflect.Int32:
+		return shim{lanes: 1, laneTypes: []api.ValueType{api.ValueTypeI32},
+			encode: func(_ Memory, _ Allocator, v reflect.Value) ([]uint64, error) {
+				return []uint64{v.Convert(reflect.TypeOf(uint32(0))).Uint()}, nil
+			},
+			decode: func(_ Memory, lanes []uint64, target reflect.Type) (reflect.Value, error) {
+				return reflect.ValueOf(uint32(lanes[0])).Convert(target), nil
+			},
+		}, nil
+	case reflect.Uint64, reflect.Int64:
+		return shim{lanes: 1, laneTypes: []api.ValueType{api.ValueTypeI64},
+			encode: func(_ Memory, _ Allocator, v reflect.Value) ([]uint64, error) {
+				return []uint64{v.Convert(reflect.TypeOf(uint64(0))).Uint()}, nil
+			},
+			decode: func(_ Memory, lanes []uint64, target reflect.Type) (reflect.Value, error) {
+				return reflect.ValueOf(lanes[0]).Convert(target), nil
+			},
+		}, nil
+	case reflect.Uint8, reflect.Int8, reflect.Uint16, reflect.Int16, reflect.Int:
+		// wasm has no native type under 32 bits (and a guest's plain "int"
+		// is 32-bit on wasm32): TinyGo lowers all of these to i32, not i64.
+		return shim{lanes: 1, laneTypes: []api.ValueType{api.ValueTypeI32},
+			encode: func(_ Memory, _ Allocator, v reflect.Value) ([]uint64, error) {
+				return []uint64{v.Convert(reflect.TypeOf(uint64(0))).Uint()}, nil
+			},
+			decode: func(_ Memory, lanes []uint64, target reflect.Type) (reflect.Value, error) {
+				return reflect.ValueOf(lanes[0]).Convert(target), nil
+			},
+		}, nil
+	case reflect.Float32:
+		return shim{lanes: 1, laneTypes: []api.ValueType{api.ValueTypeF32},
+			encode: func(_ Memory, _ Allocator, v reflect.Value) ([]uint64, error) {
+				return []uint64{uint64(api.EncodeF32(float32(v.Float())))}, nil
+			},
+			decode: func(_ Memory, lanes []uint64, target reflect.Type) (reflect.Value, error) {
+				return reflect.ValueOf(float64(api.DecodeF32(lanes[0]))).Convert(target), nil
+			},
+		}, nil
+	case reflect.Float64:
+		return shim{lanes: 1, laneTypes: []api.ValueType{api.ValueTypeF64},
+			encode: func(_ Memory, _ Allocator, v reflect.Value) ([]uint64, error) {
+				return []uint64{api.EncodeF64(v.Float())}, nil
+			},
+			decode: func(_ Memory, lanes []uint64, target reflect.Type) (reflect.Value, error) {
+				return reflect.ValueOf(api.DecodeF64(lanes[0])).Convert(target), nil
+			},
+		}, nil
+	case reflect.String:
+		return shim{lanes: 2, laneTypes: []api.ValueType{api.ValueTypeI32, api.ValueTypeI32},
+			encode: func(mem Memory, alloc Allocator, v reflect.Value) ([]uint64, error) {
+				b := []byte(v.String())
+				ptr, err := stage(mem, alloc, b)
+				if err != nil {
+					return nil, err
+				}
+				return []uint64{uint64(ptr), uint64(len(b))}, nil
+			},
+			decode: func(mem Memory, lanes []uint64, target reflect.Type) (reflect.Value, error) {
+				b, ok := mem.Read(uint32(lanes[0]), uint32(lanes[1]))
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("cellabi: out-of-bounds string read at %#x len %d", lanes[0], lanes[1])
+				}
+				return reflect.ValueOf(string(b)).Convert(target), nil
+			},
+		}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return shim{}, fmt.Errorf("cellabi: unsupported slice element type %s", t.Elem())
+		}
+		return shim{lanes: 2, laneTypes: []api.ValueType{api.ValueTypeI32, api.ValueTypeI32},
+			encode: func(mem Memory, alloc Allocator, v reflect.Value) ([]uint64, error) {
+				b := v.Bytes()
+				ptr, err := stage(mem, alloc, b)
+				if err != nil {
+					return nil, err
+				}
+				return []uint64{uint64(ptr), uint64(len(b))}, nil
+			},
+			decode: func(mem Memory, lanes []uint64, target reflect.Type) (reflect.Value, error) {
+				b, ok := mem.Read(uint32(lanes[0]), uint32(lanes[1]))
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("cellabi: out-of-bounds []byte read at %#x len %d", lanes[0], lanes[1])
+				}
+				return reflect.ValueOf(append([]byte(nil), b...)).Convert(target), nil
+			},
+		}, nil
+	case reflect.Struct:
+		return structShim(t)
+	default:
+		return shim{}, fmt.Errorf("cellabi: unsupported type %s", t)
+	}
+}
+
+// structShim flattens a small struct's exported fields into consecutive
+// wasm lanes, one shim per field, in declaration order. This matches how
+// TinyGo lowers a struct argument/return across the wasm ABI boundary for
+// the simple, fixed-layout structs cellabi targets (CoolMode-sized enums,
+// small value structs — no pointers or interfaces).
+func structShim(t reflect.Type) (shim, error) {
+	var fieldShims []shim
+	var laneTypes []api.ValueType
+	lanes := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fs, err := shimFor(f.Type)
+		if err != nil {
+			return shim{}, fmt.Errorf("cellabi: field %s.%s: %w", t.Name(), f.Name, err)
+		}
+		fieldShims = append(fieldShims, fs)
+		laneTypes = append(laneTypes, fs.laneTypes...)
+		lanes += fs.lanes
+	}
+	return shim{lanes: lanes, laneTypes: laneTypes,
+		encode: func(mem Memory, alloc Allocator, v reflect.Value) ([]uint64, error) {
+			out := make([]uint64, 0, lanes)
+			fi := 0
+			for i := 0; i < t.NumField(); i++ {
+				if !t.Field(i).IsExported() {
+					continue
+				}
+				enc, err := fieldShims[fi].encode(mem, alloc, v.Field(i))
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, enc...)
+				fi++
+			}
+			return out, nil
+		},
+		decode: func(mem Memory, rawLanes []uint64, target reflect.Type) (reflect.Value, error) {
+			out := reflect.New(target).Elem()
+			off, fi := 0, 0
+			for i := 0; i < target.NumField(); i++ {
+				if !target.Field(i).IsExported() {
+					continue
+				}
+				fs := fieldShims[fi]
+				fv, err := fs.decode(mem, rawLanes[off:off+fs.lanes], target.Field(i).Type)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				out.Field(i).Set(fv)
+				off += fs.lanes
+				fi++
+			}
+			return out, nil
+		},
+	}, nil
+}
+
+// stage copies b into guest memory via alloc, returning the pointer the
+// guest can dereference. It is a helper shared by the string and []byte
+// shims.
+func stage(mem Memory, alloc Allocator, b []byte) (uint32, error) {
+	if alloc == nil {
+		return 0, fmt.Errorf("cellabi: no allocator configured for []byte/string argument")
+	}
+	ptr, err := alloc(uint32(len(b)))
+	if err != nil {
+		return 0, fmt.Errorf("cellabi: guest allocation failed: %w", err)
+	}
+	if len(b) > 0 && !mem.Write(ptr, b) {
+		return 0, fmt.Errorf("cellabi: out-of-bounds write at %#x len %d", ptr, len(b))
+	}
+	return ptr, nil
+}
@@ -0,0 +1,147 @@
+package cellabi
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// fakeMemory is a flat byte slice standing in for a guest's linear memory,
+// used to round-trip the []byte/string shims without a real wazero module.
+type fakeMemory struct {
+	buf []byte
+}
+
+func (m *fakeMemory) Read(offset, byteCount uint32) ([]byte, bool) {
+	if uint64(offset)+uint64(byteCount) > uint64(len(m.buf)) {
+		return nil, false
+	}
+	return m.buf[offset : offset+byteCount], true
+}
+
+func (m *fakeMemory) Write(offset uint32, v []byte) bool {
+	if uint64(offset)+uint64(len(v)) > uint64(len(m.buf)) {
+		return false
+	}
+	copy(m.buf[offset:], v)
+	return true
+}
+
+func bumpAllocator(mem *fakeMemory, next *uint32) Allocator {
+	return func(size uint32) (uint32, error) {
+		ptr := *next
+		*next += size
+		return ptr, nil
+	}
+}
+
+// FuzzUint32Float32RoundTrip exercises the exact (uint32, float32) uint32
+// signature process_sensor_data uses today, so a future ABI change to
+// richer types cannot silently break the guest's existing contract.
+func FuzzUint32Float32RoundTrip(f *testing.F) {
+	f.Add(uint32(0), float32(1200.0))
+	f.Add(uint32(1), float32(35.5))
+	f.Add(uint32(math.MaxUint32), float32(math.MaxFloat32))
+
+	sensorShim, err := shimFor(reflect.TypeOf(uint32(0)))
+	if err != nil {
+		f.Fatal(err)
+	}
+	valueShim, err := shimFor(reflect.TypeOf(float32(0)))
+	if err != nil {
+		f.Fatal(err)
+	}
+	resultShim, err := shimFor(reflect.TypeOf(uint32(0)))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, sensorID uint32, value float32) {
+		mem := &fakeMemory{}
+
+		sensorLanes, err := sensorShim.encode(mem, nil, reflect.ValueOf(sensorID))
+		if err != nil {
+			t.Fatalf("encode sensorID: %v", err)
+		}
+		valueLanes, err := valueShim.encode(mem, nil, reflect.ValueOf(value))
+		if err != nil {
+			t.Fatalf("encode value: %v", err)
+		}
+
+		gotSensor, err := sensorShim.decode(mem, sensorLanes, reflect.TypeOf(uint32(0)))
+		if err != nil || uint32(gotSensor.Uint()) != sensorID {
+			t.Fatalf("sensorID round-trip: got %v err %v, want %d", gotSensor, err, sensorID)
+		}
+		gotValue, err := valueShim.decode(mem, valueLanes, reflect.TypeOf(float32(0)))
+		if err != nil {
+			t.Fatalf("value decode: %v", err)
+		}
+		if math.Float32bits(float32(gotValue.Float())) != math.Float32bits(value) && !(math.IsNaN(float64(value)) && math.IsNaN(gotValue.Float())) {
+			t.Fatalf("value round-trip: got %v, want %v", gotValue.Float(), value)
+		}
+
+		result := uint32(0)
+		resultLanes, err := resultShim.encode(mem, nil, reflect.ValueOf(result))
+		if err != nil {
+			t.Fatalf("encode result: %v", err)
+		}
+		gotResult, err := resultShim.decode(mem, resultLanes, reflect.TypeOf(uint32(0)))
+		if err != nil || uint32(gotResult.Uint()) != result {
+			t.Fatalf("result round-trip: got %v err %v, want %d", gotResult, err, result)
+		}
+	})
+}
+
+// TestStringRoundTrip exercises the richer []byte/string shims cellabi adds
+// beyond process_sensor_data's current uint32/float32-only ABI.
+func TestStringRoundTrip(t *testing.T) {
+	mem := &fakeMemory{buf: make([]byte, 256)}
+	var next uint32
+	alloc := bumpAllocator(mem, &next)
+
+	s, err := shimFor(reflect.TypeOf(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"", "ok", "cell trapped: out of bounds"} {
+		lanes, err := s.encode(mem, alloc, reflect.ValueOf(want))
+		if err != nil {
+			t.Fatalf("encode %q: %v", want, err)
+		}
+		got, err := s.decode(mem, lanes, reflect.TypeOf(""))
+		if err != nil || got.String() != want {
+			t.Fatalf("round-trip %q: got %q err %v", want, got, err)
+		}
+	}
+}
+
+// TestStructRoundTrip covers the small fixed-layout structs cellabi expects
+// firmware authors to pass, e.g. an actuator call bundling a power level
+// with a mode enum.
+func TestStructRoundTrip(t *testing.T) {
+	type CoolMode uint8
+	type CoolingRequest struct {
+		Power uint8
+		Mode  CoolMode
+	}
+
+	s, err := shimFor(reflect.TypeOf(CoolingRequest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := CoolingRequest{Power: 80, Mode: CoolMode(2)}
+	mem := &fakeMemory{}
+	lanes, err := s.encode(mem, nil, reflect.ValueOf(want))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	gotVal, err := s.decode(mem, lanes, reflect.TypeOf(CoolingRequest{}))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := gotVal.Interface().(CoolingRequest); got != want {
+		t.Fatalf("round-trip: got %+v, want %+v", got, want)
+	}
+}
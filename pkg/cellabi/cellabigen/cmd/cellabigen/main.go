@@ -0,0 +1,63 @@
+// Command cellabigen scans a Go source file for interface declarations and
+// writes the matching TinyGo guest stubs and wazero host registration,
+// per pkg/cellabi/cellabigen.
+//
+// Usage:
+//
+//	cellabigen -in actuators.go -module env -guest-out actuators_guest.go -host-out actuators_host.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Phoenixai36/PhoenixDemiGod-System/pkg/cellabi/cellabigen"
+)
+
+func main() {
+	in := flag.String("in", "", "Go source file declaring the actuator/sensor interface")
+	module := flag.String("module", "env", "wasm import module name to generate the host registration under")
+	guestOut := flag.String("guest-out", "", "output path for the generated TinyGo guest stubs")
+	hostOut := flag.String("host-out", "", "output path for the generated wazero host registration")
+	flag.Parse()
+
+	if *in == "" || *guestOut == "" || *hostOut == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*in, *module, *guestOut, *hostOut); err != nil {
+		fmt.Fprintln(os.Stderr, "cellabigen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, module, guestOut, hostOut string) error {
+	ifaces, err := cellabigen.ParseFile(in, module)
+	if err != nil {
+		return err
+	}
+	if len(ifaces) == 0 {
+		return fmt.Errorf("no interface declarations found in %s", in)
+	}
+
+	var guest, host []byte
+	for _, iface := range ifaces {
+		g, err := cellabigen.GenerateGuest(iface)
+		if err != nil {
+			return fmt.Errorf("generating guest stubs for %s: %w", iface.Name, err)
+		}
+		h, err := cellabigen.GenerateHost(iface)
+		if err != nil {
+			return fmt.Errorf("generating host registration for %s: %w", iface.Name, err)
+		}
+		guest = append(guest, g...)
+		host = append(host, h...)
+	}
+
+	if err := os.WriteFile(guestOut, guest, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(hostOut, host, 0o644)
+}
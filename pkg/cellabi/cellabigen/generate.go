@@ -0,0 +1,211 @@
+// Package cellabigen is the "mecha"-style code generator behind cellabi: it
+// scans a Go interface describing a cell's actuators (or sensors) and emits
+// the TinyGo guest stubs and the wazero host registration from that single
+// declaration, so the contract between guest and host cannot drift out of
+// sync.
+//
+// A typical source interface:
+//
+//	type Actuators interface {
+//	    Fan(power uint32) uint32
+//	    Cooling(power, mode uint32) uint32
+//	}
+//
+// produces a guest file with one //go:wasmimport stub per method and a host
+// file with one cellabi.BindHostFunc registration per method.
+//
+// Go's go:wasmimport directive only accepts int32/uint32/int64/uint64/
+// float32/float64/uintptr/unsafe.Pointer parameters and a single result of
+// the same set — no error, no multiple returns, no uint8/int8/uint16/int16.
+// GenerateGuest rejects a method outside that set rather than emit an
+// import stub that fails to build; cellabi's own BindHostFunc, which
+// reflects over the interface on the host side instead of declaring a
+// guest-side import, is where the richer types (float64, []byte, string,
+// small structs) documented on pkg/cellabi apply.
+package cellabigen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"text/template"
+)
+
+// Method describes one interface method to generate stubs for.
+type Method struct {
+	Name    string
+	Params  []Field
+	Results []Field
+}
+
+// Field is a single parameter or result, by its Go source type spelling.
+type Field struct {
+	Name string
+	Type string
+}
+
+// Interface is a single `type X interface { ... }` declaration found in a
+// source file, ready for template expansion.
+type Interface struct {
+	Name       string
+	ModuleName string // wasm import module the methods are grouped under
+	Methods    []Method
+}
+
+// ParseFile finds every interface declaration in the Go source at path and
+// returns them as Interfaces, grouping imports under moduleName (the wasm
+// import module name the host registers them against, e.g. "env").
+func ParseFile(path, moduleName string) ([]Interface, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.AllErrors)
+	if err != nil {
+		return nil, fmt.Errorf("cellabigen: parsing %s: %w", path, err)
+	}
+
+	var out []Interface
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		it, ok := ts.Type.(*ast.InterfaceType)
+		if !ok {
+			return true
+		}
+		iface := Interface{Name: ts.Name.Name, ModuleName: moduleName}
+		for _, m := range it.Methods.List {
+			ft, ok := m.Type.(*ast.FuncType)
+			if !ok || len(m.Names) == 0 {
+				continue
+			}
+			iface.Methods = append(iface.Methods, Method{
+				Name:    m.Names[0].Name,
+				Params:  fieldsOf(ft.Params),
+				Results: fieldsOf(ft.Results),
+			})
+		}
+		out = append(out, iface)
+		return true
+	})
+	return out, nil
+}
+
+func fieldsOf(fl *ast.FieldList) []Field {
+	if fl == nil {
+		return nil
+	}
+	var out []Field
+	for _, f := range fl.List {
+		typ := exprString(f.Type)
+		if len(f.Names) == 0 {
+			out = append(out, Field{Name: "", Type: typ})
+			continue
+		}
+		for _, n := range f.Names {
+			out = append(out, Field{Name: n.Name, Type: typ})
+		}
+	}
+	return out
+}
+
+func exprString(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	default:
+		return fmt.Sprintf("%T", e)
+	}
+}
+
+var guestTemplate = template.Must(template.New("guest").Parse(`// Code generated by cellabigen. DO NOT EDIT.
+
+package main
+
+{{range .Methods}}
+//go:wasmimport {{$.ModuleName}} {{.Name}}
+func {{.Name}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) {{range .Results}}{{.Type}} {{end}}
+{{end}}
+`))
+
+var hostTemplate = template.Must(template.New("host").Parse(`// Code generated by cellabigen. DO NOT EDIT.
+
+package wasmhost
+
+import (
+	"github.com/tetratelabs/wazero"
+
+	"github.com/Phoenixai36/PhoenixDemiGod-System/pkg/cellabi"
+)
+
+// Register{{.Name}} binds impl's methods as "{{.ModuleName}}" host imports
+// using cellabi's reflective marshaling, generated from the {{.Name}}
+// interface.
+func Register{{.Name}}(builder wazero.HostModuleBuilder, impl {{.Name}}) error {
+{{range .Methods}}	if err := cellabi.BindHostFunc(builder, "{{.Name}}", impl.{{.Name}}); err != nil {
+		return err
+	}
+{{end}}	return nil
+}
+`))
+
+// wasmimportLegalTypes is the full set of Go types accepted as a parameter
+// or single result on a //go:wasmimport function; anything else (error,
+// uint8/int8/uint16/int16, multiple results, structs, slices, strings) is
+// rejected by the Go compiler.
+var wasmimportLegalTypes = map[string]bool{
+	"int32": true, "uint32": true, "int64": true, "uint64": true,
+	"float32": true, "float64": true, "uintptr": true, "unsafe.Pointer": true,
+}
+
+// validateWasmimportSignature reports an error if method's params or result
+// fall outside wasmimportLegalTypes, naming the offending method and type so
+// the caller can fix the source interface rather than ship a guest stub that
+// fails to build.
+func validateWasmimportSignature(method Method) error {
+	for _, p := range method.Params {
+		if !wasmimportLegalTypes[p.Type] {
+			return fmt.Errorf("cellabigen: method %s: parameter type %q is not legal for go:wasmimport (allowed: int32, uint32, int64, uint64, float32, float64, uintptr, unsafe.Pointer)", method.Name, p.Type)
+		}
+	}
+	if len(method.Results) > 1 {
+		return fmt.Errorf("cellabigen: method %s: go:wasmimport allows at most one result, got %d", method.Name, len(method.Results))
+	}
+	if len(method.Results) == 1 && !wasmimportLegalTypes[method.Results[0].Type] {
+		return fmt.Errorf("cellabigen: method %s: result type %q is not legal for go:wasmimport (allowed: int32, uint32, int64, uint64, float32, float64, uintptr, unsafe.Pointer)", method.Name, method.Results[0].Type)
+	}
+	return nil
+}
+
+// GenerateGuest renders the TinyGo //go:wasmimport stubs for iface. It
+// rejects iface before rendering if any method's signature is not legal for
+// go:wasmimport — richer types (error, uint8, ...) belong on the host side,
+// bound via GenerateHost/cellabi.BindHostFunc instead.
+func GenerateGuest(iface Interface) ([]byte, error) {
+	for _, m := range iface.Methods {
+		if err := validateWasmimportSignature(m); err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	if err := guestTemplate.Execute(&buf, iface); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateHost renders the wazero HostModuleBuilder registration for iface.
+func GenerateHost(iface Interface) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := hostTemplate.Execute(&buf, iface); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
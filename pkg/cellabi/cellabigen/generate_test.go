@@ -0,0 +1,82 @@
+package cellabigen
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGenerateGuestEmitsWasmimportStubs exercises a legal interface (only
+// types go:wasmimport actually accepts) and checks the rendered stub matches
+// the import module and signature.
+func TestGenerateGuestEmitsWasmimportStubs(t *testing.T) {
+	iface := Interface{
+		Name:       "Actuators",
+		ModuleName: "env",
+		Methods: []Method{
+			{
+				Name:    "ActuateFan",
+				Params:  []Field{{Name: "power", Type: "uint32"}},
+				Results: []Field{{Type: "uint32"}},
+			},
+		},
+	}
+
+	src, err := GenerateGuest(iface)
+	if err != nil {
+		t.Fatalf("GenerateGuest: %v", err)
+	}
+	for _, want := range []string{
+		"//go:wasmimport env ActuateFan",
+		"func ActuateFan(power uint32) uint32",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
+
+// TestGenerateGuestRejectsIllegalTypes covers the bug the package doc used
+// to demonstrate by accident: Go's go:wasmimport directive does not accept
+// error results, sub-32-bit integers, or multiple return values, so
+// GenerateGuest must refuse these rather than emit a stub that fails to
+// build.
+func TestGenerateGuestRejectsIllegalTypes(t *testing.T) {
+	cases := []struct {
+		name   string
+		method Method
+	}{
+		{
+			name: "error result",
+			method: Method{
+				Name:    "Fan",
+				Params:  []Field{{Name: "power", Type: "uint32"}},
+				Results: []Field{{Type: "error"}},
+			},
+		},
+		{
+			name: "uint8 parameter",
+			method: Method{
+				Name:    "Fan",
+				Params:  []Field{{Name: "power", Type: "uint8"}},
+				Results: []Field{{Type: "uint32"}},
+			},
+		},
+		{
+			name: "multiple results",
+			method: Method{
+				Name:    "Fan",
+				Params:  []Field{{Name: "power", Type: "uint32"}},
+				Results: []Field{{Type: "uint32"}, {Type: "uint32"}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			iface := Interface{Name: "Actuators", ModuleName: "env", Methods: []Method{c.method}}
+			if _, err := GenerateGuest(iface); err == nil {
+				t.Fatalf("GenerateGuest: expected error for %s, got nil", c.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package cellupdate
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wasmBytes := []byte("fake wasm module")
+	sig := ed25519.Sign(priv, wasmBytes)
+
+	if err := VerifySignature(pub, wasmBytes, sig); err != nil {
+		t.Fatalf("expected valid signature to verify, got %v", err)
+	}
+
+	tampered := append([]byte(nil), wasmBytes...)
+	tampered[0] ^= 0xff
+	if err := VerifySignature(pub, tampered, sig); err == nil {
+		t.Fatal("expected tampered module to fail verification")
+	}
+}
+
+func TestDirSlotStoreRoundTrip(t *testing.T) {
+	store := DirSlotStore{Dir: filepath.Join(t.TempDir(), "slot")}
+
+	empty, err := store.Load()
+	if err != nil || empty != nil {
+		t.Fatalf("expected empty slot, got %v, %v", empty, err)
+	}
+
+	want := []byte("last known good module bytes")
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Saving again must atomically replace, never leave a partial file.
+	want2 := []byte("a newer known good module")
+	if err := store.Save(want2); err != nil {
+		t.Fatalf("Save (second): %v", err)
+	}
+	got2, err := store.Load()
+	if err != nil || string(got2) != string(want2) {
+		t.Fatalf("got %q, %v, want %q", got2, err, want2)
+	}
+
+	if _, err := os.Stat(store.path() + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected temp file to be renamed away, stat err = %v", err)
+	}
+}
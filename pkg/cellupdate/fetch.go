@@ -0,0 +1,46 @@
+package cellupdate
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultChunkSize is used by Fetch when the caller does not need a
+// smaller chunk to fit a particularly constrained MQTT payload limit.
+const DefaultChunkSize = 32 * 1024
+
+// Fetch downloads a full module over t in chunkSize pieces, resuming after
+// the bytes already in resumeFrom (nil for a fresh download) so a caller
+// that persists resumeFrom
+// across restarts can continue an interrupted OTA transfer without
+// refetching bytes already on disk.
+func Fetch(ctx context.Context, t Transport, resumeFrom []byte, chunkSize int64) ([]byte, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	buf := append([]byte(nil), resumeFrom...)
+	offset := int64(len(buf))
+
+	for {
+		chunk, totalSize, err := t.FetchRange(ctx, offset, chunkSize)
+		if err != nil {
+			return buf, fmt.Errorf("cellupdate: fetching chunk at offset %d: %w", offset, err)
+		}
+		if len(chunk) == 0 {
+			return buf, fmt.Errorf("cellupdate: empty chunk at offset %d", offset)
+		}
+
+		buf = append(buf, chunk...)
+		offset += int64(len(chunk))
+
+		if totalSize > 0 && offset >= totalSize {
+			return buf, nil
+		}
+		if totalSize == 0 && int64(len(chunk)) < chunkSize {
+			// No Content-Range to confirm total size; a short read is the
+			// best signal we have that this was the last chunk.
+			return buf, nil
+		}
+	}
+}
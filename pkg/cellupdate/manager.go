@@ -0,0 +1,237 @@
+package cellupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Phoenixai36/PhoenixDemiGod-System/pkg/wasmhost"
+)
+
+// RollbackPolicy bounds how many traps a newly activated cell may have
+// within a trailing window before Manager reverts to the last-known-good
+// module automatically.
+type RollbackPolicy struct {
+	MaxTraps int
+	Window   time.Duration
+}
+
+// DefaultRollbackPolicy tolerates at most 3 traps in 10 seconds after
+// activation before reverting, generous enough to absorb a cell's first
+// few calls against live sensor noise without masking a genuinely broken
+// update.
+func DefaultRollbackPolicy() RollbackPolicy {
+	return RollbackPolicy{MaxTraps: 3, Window: 10 * time.Second}
+}
+
+// activeCell guards the live *wasmhost.Host behind a RWMutex so Manager can
+// swap in a new cell only once every in-flight ProcessSensorData call has
+// finished (the Lock taken by Swap waits out any readers), without
+// blocking concurrent callers under normal operation.
+type activeCell struct {
+	mu   sync.RWMutex
+	host *wasmhost.Host
+}
+
+func (a *activeCell) processSensorData(ctx context.Context, sensorID uint32, value float32) (uint32, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.host.ProcessSensorData(ctx, sensorID, value)
+}
+
+// swap drains in-flight calls, installs next and returns the previous host;
+// it does not close the previous host.
+func (a *activeCell) swap(ctx context.Context, next *wasmhost.Host) (previous *wasmhost.Host) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	previous = a.host
+	a.host = next
+	return previous
+}
+
+// Manager runs the OTA channel for a single cell slot: fetching, verifying,
+// validating and atomically swapping in new modules, with an automatic
+// rollback if the newly activated cell starts trapping.
+type Manager struct {
+	pubKey    ed25519.PublicKey
+	reg       wasmhost.ActuatorRegistry
+	cfg       wasmhost.HostConfig
+	slotStore SlotStore
+	rollback  RollbackPolicy
+
+	active *activeCell
+
+	mu          sync.Mutex // guards the fields below
+	lastReason  string
+	traps       []time.Time
+	revertTimer *time.Timer
+}
+
+// NewManager starts a Manager running initialWasm as the active cell,
+// verifying pubKey against every future update before it is considered.
+func NewManager(ctx context.Context, initialWasm []byte, pubKey ed25519.PublicKey, reg wasmhost.ActuatorRegistry, cfg wasmhost.HostConfig, slotStore SlotStore, rollback RollbackPolicy) (*Manager, error) {
+	host, err := wasmhost.NewHost(ctx, initialWasm, reg, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cellupdate: loading initial cell: %w", err)
+	}
+	if err := slotStore.Save(initialWasm); err != nil {
+		host.Close(ctx)
+		return nil, fmt.Errorf("cellupdate: seeding slot store: %w", err)
+	}
+
+	return &Manager{
+		pubKey: pubKey, reg: reg, cfg: cfg, slotStore: slotStore, rollback: rollback,
+		active: &activeCell{host: host},
+	}, nil
+}
+
+// ProcessSensorData forwards to the active cell and feeds the result into
+// the rollback timer, so a trap observed via normal operation (not just a
+// synthetic validation call) counts towards an automatic revert.
+func (m *Manager) ProcessSensorData(ctx context.Context, sensorID uint32, value float32) (uint32, error) {
+	result, err := m.active.processSensorData(ctx, sensorID, value)
+	if err != nil && wasmhost.IsTrap(err) {
+		m.recordTrap(ctx)
+	}
+	return result, err
+}
+
+// LastFailureReason reports why the most recent Activate call was rejected,
+// or that the manager rolled back (or failed to roll back) to the
+// last-known-good module, for firmware to surface to an operator. It is
+// empty after a successful activation.
+func (m *Manager) LastFailureReason() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastReason
+}
+
+// Activate fetches nothing itself — callers assemble wasmBytes via Fetch —
+// but performs every safety step before it takes effect: signature
+// verification, a scratch pre-instantiation against the production import
+// set, and only then an atomic swap that drains in-flight calls on the
+// cell it replaces. On any failure the previous module keeps running and
+// the reason is recorded for LastFailureReason.
+func (m *Manager) Activate(ctx context.Context, wasmBytes, sig []byte) error {
+	if err := VerifySignature(m.pubKey, wasmBytes, sig); err != nil {
+		m.setFailure(err)
+		return err
+	}
+
+	scratch, err := wasmhost.NewHost(ctx, wasmBytes, m.reg, m.cfg)
+	if err != nil {
+		wrapped := fmt.Errorf("cellupdate: validating candidate module: %w", err)
+		m.setFailure(wrapped)
+		return wrapped
+	}
+	scratch.Close(ctx)
+
+	newHost, err := wasmhost.NewHost(ctx, wasmBytes, m.reg, m.cfg)
+	if err != nil {
+		wrapped := fmt.Errorf("cellupdate: instantiating candidate module for activation: %w", err)
+		m.setFailure(wrapped)
+		return wrapped
+	}
+
+	previous := m.active.swap(ctx, newHost)
+
+	m.mu.Lock()
+	m.lastReason = ""
+	m.traps = nil
+	m.mu.Unlock()
+
+	m.armRollback(ctx, wasmBytes)
+
+	if previous != nil {
+		previous.Close(ctx)
+	}
+	return nil
+}
+
+func (m *Manager) setFailure(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastReason = err.Error()
+}
+
+// armRollback saves the module that is about to become the new
+// last-known-good candidate once it survives the rollback window, and
+// starts a timer that checks the trap count at the window's end.
+func (m *Manager) armRollback(ctx context.Context, activatedWasm []byte) {
+	if m.rollback.Window <= 0 {
+		return
+	}
+	m.mu.Lock()
+	if m.revertTimer != nil {
+		m.revertTimer.Stop()
+	}
+	m.revertTimer = time.AfterFunc(m.rollback.Window, func() {
+		if m.trapCountWithinWindow() > m.rollback.MaxTraps {
+			m.revert(ctx)
+			return
+		}
+		// The activated cell proved itself stable: it becomes the new
+		// last-known-good module for any future rollback.
+		_ = m.slotStore.Save(activatedWasm)
+	})
+	m.mu.Unlock()
+}
+
+func (m *Manager) recordTrap(ctx context.Context) {
+	m.mu.Lock()
+	m.traps = append(m.traps, time.Now())
+	count := m.trapCountWithinWindowLocked()
+	m.mu.Unlock()
+
+	if count > m.rollback.MaxTraps {
+		m.revert(ctx)
+	}
+}
+
+func (m *Manager) trapCountWithinWindow() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.trapCountWithinWindowLocked()
+}
+
+func (m *Manager) trapCountWithinWindowLocked() int {
+	cutoff := time.Now().Add(-m.rollback.Window)
+	count := 0
+	for _, t := range m.traps {
+		if t.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// revert swaps the active cell back to the last-known-good module stored
+// in the slot store, closing whatever is running now.
+func (m *Manager) revert(ctx context.Context) {
+	goodWasm, err := m.slotStore.Load()
+	if err != nil || goodWasm == nil {
+		m.setFailure(fmt.Errorf("cellupdate: rollback triggered but no last-known-good module available: %v", err))
+		return
+	}
+
+	goodHost, err := wasmhost.NewHost(ctx, goodWasm, m.reg, m.cfg)
+	if err != nil {
+		m.setFailure(fmt.Errorf("cellupdate: rollback failed to instantiate last-known-good module: %w", err))
+		return
+	}
+
+	previous := m.active.swap(ctx, goodHost)
+	m.mu.Lock()
+	if m.revertTimer != nil {
+		m.revertTimer.Stop()
+	}
+	m.traps = nil
+	m.lastReason = "rolled back to last-known-good module after exceeding trap threshold"
+	m.mu.Unlock()
+
+	if previous != nil {
+		previous.Close(ctx)
+	}
+}
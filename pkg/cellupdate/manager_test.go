@@ -0,0 +1,160 @@
+package cellupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Phoenixai36/PhoenixDemiGod-System/pkg/wasmhost"
+)
+
+// noopActuators discards actuation requests, standing in for real hardware
+// in Manager tests.
+type noopActuators struct{}
+
+func (noopActuators) ActuateFan(context.Context, uint32) uint32     { return 1 }
+func (noopActuators) ActuateCooling(context.Context, uint32) uint32 { return 1 }
+
+// The following hand-assemble the smallest possible wasm modules (no
+// TinyGo toolchain needed) exporting process_sensor_data(i32,f32)->i32, so
+// Manager.Activate/revert can be exercised end-to-end against a real
+// wazero instantiation rather than just its own bookkeeping.
+
+func uleb128(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+func wName(s string) []byte { return append(uleb128(len(s)), []byte(s)...) }
+
+func wVec(items ...[]byte) []byte {
+	out := uleb128(len(items))
+	for _, it := range items {
+		out = append(out, it...)
+	}
+	return out
+}
+
+func wSection(id byte, content []byte) []byte {
+	out := []byte{id}
+	out = append(out, uleb128(len(content))...)
+	return append(out, content...)
+}
+
+const (
+	valI32 = 0x7f
+	valF32 = 0x7d
+)
+
+// buildProcessSensorDataModule assembles a module exporting a single
+// process_sensor_data(sensor_id uint32, value float32) uint32 function
+// whose body is exactly body (no locals), e.g. "i32.const 1; end" for a
+// well-behaved cell or "unreachable; end" for one that always traps.
+func buildProcessSensorDataModule(body []byte) []byte {
+	ft := []byte{0x60, 0x02, valI32, valF32, 0x01, valI32}
+	typeSec := wSection(1, wVec(ft))
+	funcSec := wSection(3, wVec([]byte{0x00}))
+	exportSec := wSection(7, wVec(append(wName("process_sensor_data"), 0x00, 0x00)))
+	entry := append([]byte{0x00}, body...) // 0 local-decl groups
+	codeSec := wSection(10, wVec(append(uleb128(len(entry)), entry...)))
+
+	out := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00} // \0asm, version 1
+	out = append(out, typeSec...)
+	out = append(out, funcSec...)
+	out = append(out, exportSec...)
+	out = append(out, codeSec...)
+	return out
+}
+
+func buildGoodCell() []byte {
+	return buildProcessSensorDataModule([]byte{0x41, 0x01, 0x0b}) // i32.const 1; end
+}
+
+func buildTrappingCell() []byte {
+	return buildProcessSensorDataModule([]byte{0x00, 0x0b}) // unreachable; end
+}
+
+// TestActivateSwapsInNewModule checks the ordinary success path: a signed,
+// valid module becomes the active cell and answers ProcessSensorData.
+func TestActivateSwapsInNewModule(t *testing.T) {
+	ctx := context.Background()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initial := buildGoodCell()
+	store := DirSlotStore{Dir: filepath.Join(t.TempDir(), "slot")}
+	mgr, err := NewManager(ctx, initial, pub, noopActuators{}, wasmhost.DefaultHostConfig(), store, DefaultRollbackPolicy())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	next := buildGoodCell()
+	sig := ed25519.Sign(priv, next)
+	if err := mgr.Activate(ctx, next, sig); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+
+	res, err := mgr.ProcessSensorData(ctx, 0, 1.0)
+	if err != nil || res != 1 {
+		t.Fatalf("ProcessSensorData after activate: res=%d err=%v, want 1, nil", res, err)
+	}
+	if reason := mgr.LastFailureReason(); reason != "" {
+		t.Fatalf("LastFailureReason = %q, want empty after a clean activate", reason)
+	}
+}
+
+// TestActivateAutoRevertsAfterTrapThreshold drives the newly activated,
+// always-trapping cell past RollbackPolicy.MaxTraps and checks Manager
+// reverts to the slot-stored last-known-good module on its own.
+func TestActivateAutoRevertsAfterTrapThreshold(t *testing.T) {
+	ctx := context.Background()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	good := buildGoodCell()
+	store := DirSlotStore{Dir: filepath.Join(t.TempDir(), "slot")}
+	rollback := RollbackPolicy{MaxTraps: 2, Window: time.Minute}
+	mgr, err := NewManager(ctx, good, pub, noopActuators{}, wasmhost.DefaultHostConfig(), store, rollback)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	bad := buildTrappingCell()
+	sig := ed25519.Sign(priv, bad)
+	if err := mgr.Activate(ctx, bad, sig); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+
+	for i := 0; i < rollback.MaxTraps+1; i++ {
+		if _, err := mgr.ProcessSensorData(ctx, 0, 1.0); err == nil {
+			t.Fatalf("call %d: expected the trapping cell to error", i)
+		}
+	}
+
+	res, err := mgr.ProcessSensorData(ctx, 0, 1.0)
+	if err != nil {
+		t.Fatalf("ProcessSensorData after auto-revert: %v", err)
+	}
+	if res != 1 {
+		t.Fatalf("res = %d, want 1 from the reverted last-known-good module", res)
+	}
+	if reason := mgr.LastFailureReason(); reason == "" {
+		t.Fatal("LastFailureReason = \"\", want the auto-revert to be recorded")
+	}
+}
@@ -0,0 +1,57 @@
+package cellupdate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SlotStore persists the last-known-good module so a rollback does not
+// depend on re-fetching it over the same slow link the failed update came
+// from. Implementations are expected to be small and atomic — a single
+// rename-on-write is enough, since only one module is ever kept.
+type SlotStore interface {
+	// Save atomically replaces the stored module.
+	Save(wasmBytes []byte) error
+	// Load returns the stored module, or (nil, nil) if none has been
+	// saved yet.
+	Load() ([]byte, error)
+}
+
+// DirSlotStore stores a single module as a file in Dir, writing to a
+// temporary file and renaming it into place so a crash mid-write can never
+// leave a truncated module behind.
+type DirSlotStore struct {
+	Dir string
+}
+
+func (s DirSlotStore) path() string {
+	return filepath.Join(s.Dir, "last-known-good.wasm")
+}
+
+// Save writes wasmBytes to the slot, atomically.
+func (s DirSlotStore) Save(wasmBytes []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("cellupdate: creating slot dir: %w", err)
+	}
+	tmp := s.path() + ".tmp"
+	if err := os.WriteFile(tmp, wasmBytes, 0o644); err != nil {
+		return fmt.Errorf("cellupdate: writing slot file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path()); err != nil {
+		return fmt.Errorf("cellupdate: committing slot file: %w", err)
+	}
+	return nil
+}
+
+// Load reads the stored module, returning (nil, nil) if the slot is empty.
+func (s DirSlotStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(s.path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cellupdate: reading slot file: %w", err)
+	}
+	return data, nil
+}
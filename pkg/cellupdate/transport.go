@@ -0,0 +1,93 @@
+// Package cellupdate delivers new WASM cells to a running firmware host
+// over the kind of slow, unreliable link the README's OTA story assumes:
+// it fetches a module in resumable chunks, verifies an Ed25519 signature
+// against a firmware-embedded key, validates it by pre-instantiating it in
+// a scratch wazero runtime, and only then atomically swaps it in for the
+// cell it replaces — draining in-flight calls first and rolling back if
+// the new cell turns out to trap.
+package cellupdate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Transport fetches one byte range of a remote module. FetchRange must be
+// safe to call repeatedly for the same offset after a dropped connection,
+// so a resumable download can simply retry the last incomplete range.
+type Transport interface {
+	// FetchRange reads length bytes starting at offset and returns them
+	// alongside the remote object's total size, so callers that started
+	// without knowing it can size their output buffer.
+	FetchRange(ctx context.Context, offset int64, length int64) (data []byte, totalSize int64, err error)
+}
+
+// HTTPRangeTransport fetches a module over plain HTTP Range requests,
+// suited to links where a full-body GET is too likely to be interrupted
+// to complete in one attempt.
+type HTTPRangeTransport struct {
+	Client *http.Client
+	URL    string
+}
+
+// FetchRange issues a single Range: bytes=offset-(offset+length-1) request.
+func (t HTTPRangeTransport) FetchRange(ctx context.Context, offset, length int64) ([]byte, int64, error) {
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cellupdate: building range request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cellupdate: range request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Expected: the server honored our Range header.
+	case http.StatusOK:
+		// A 200 means the server ignored Range and is sending the whole
+		// object. That's only safe to treat as "the range we asked for" when
+		// we asked for the range starting at 0 — anything else and the body
+		// is the full object, not a continuation, and appending it onto an
+		// already-fetched prefix would corrupt the buffer.
+		if offset != 0 {
+			return nil, 0, fmt.Errorf("cellupdate: server returned full object (status 200) for range request at offset %d; server does not support Range", offset)
+		}
+	default:
+		return nil, 0, fmt.Errorf("cellupdate: unexpected status %s fetching range", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cellupdate: reading range body: %w", err)
+	}
+
+	totalSize := resp.ContentLength
+	if cr := resp.Header.Get("Content-Range"); cr != "" {
+		if n, err := parseContentRangeSize(cr); err == nil {
+			totalSize = n
+		}
+	}
+	return data, totalSize, nil
+}
+
+// parseContentRangeSize extracts the total size from a "bytes a-b/total"
+// Content-Range header value.
+func parseContentRangeSize(headerValue string) (int64, error) {
+	var start, end, total int64
+	_, err := fmt.Sscanf(headerValue, "bytes %d-%d/%d", &start, &end, &total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,57 @@
+package cellupdate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHTTPRangeTransportAcceptsFullBodyOnlyAtOffsetZero checks that a server
+// that ignores the Range header and returns the whole object with 200 is
+// only accepted as the first chunk of a fresh download (offset 0) — at any
+// later offset it would silently append the full object onto an
+// already-fetched prefix and corrupt the buffer.
+func TestHTTPRangeTransportAcceptsFullBodyOnlyAtOffsetZero(t *testing.T) {
+	body := []byte("the entire module, ignoring Range")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	transport := HTTPRangeTransport{URL: srv.URL}
+
+	data, _, err := transport.FetchRange(context.Background(), 0, 8)
+	if err != nil {
+		t.Fatalf("FetchRange at offset 0: %v", err)
+	}
+	if string(data) != string(body) {
+		t.Fatalf("FetchRange at offset 0: got %q, want %q", data, body)
+	}
+
+	if _, _, err := transport.FetchRange(context.Background(), 8, 8); err == nil {
+		t.Fatal("FetchRange at offset 8: expected error for status-200 response, got nil")
+	}
+}
+
+func TestHTTPRangeTransportPartialContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 8-15/32")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("chunkdata"))
+	}))
+	defer srv.Close()
+
+	transport := HTTPRangeTransport{URL: srv.URL}
+	data, totalSize, err := transport.FetchRange(context.Background(), 8, 8)
+	if err != nil {
+		t.Fatalf("FetchRange: %v", err)
+	}
+	if string(data) != "chunkdata" {
+		t.Fatalf("FetchRange: got %q", data)
+	}
+	if totalSize != 32 {
+		t.Fatalf("totalSize = %d, want 32", totalSize)
+	}
+}
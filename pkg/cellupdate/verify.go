@@ -0,0 +1,19 @@
+package cellupdate
+
+import (
+	"crypto/ed25519"
+	"fmt"
+)
+
+// VerifySignature checks sig against wasmBytes using pubKey, the firmware's
+// embedded Ed25519 public key. An OTA module that fails this check must
+// never be instantiated, let alone activated.
+func VerifySignature(pubKey ed25519.PublicKey, wasmBytes, sig []byte) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("cellupdate: invalid public key size %d", len(pubKey))
+	}
+	if !ed25519.Verify(pubKey, wasmBytes, sig) {
+		return fmt.Errorf("cellupdate: signature verification failed")
+	}
+	return nil
+}
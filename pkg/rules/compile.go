@@ -0,0 +1,293 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+)
+
+// Compile renders p as a standalone TinyGo source file that builds to WASM
+// with `tinygo build -target=wasi`, exporting process_sensor_data with the
+// same (sensor_id uint32, value float32) uint32 signature the hand-written
+// guest uses today — so a compiled policy is a drop-in replacement cell.
+func Compile(p Policy) ([]byte, error) {
+	if err := validate(p); err != nil {
+		return nil, fmt.Errorf("rules: invalid policy: %w", err)
+	}
+
+	sensorByName := make(map[string]Sensor, len(p.Sensors))
+	for _, s := range p.Sensors {
+		sensorByName[s.Name] = s
+	}
+
+	rules := make([]Rule, len(p.Rules))
+	copy(rules, p.Rules)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority > rules[j].Priority })
+
+	data := struct {
+		Sensors []Sensor
+		Rules   []compiledRule
+	}{Sensors: p.Sensors}
+
+	for i, r := range rules {
+		cond, err := compileExpr(r.Condition, sensorByName)
+		if err != nil {
+			return nil, fmt.Errorf("rules: rule %q: %w", r.Name, err)
+		}
+		data.Rules = append(data.Rules, compiledRule{
+			Index: i, Rule: r, ConditionGo: cond,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := guestTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rules: rendering TinyGo source: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func validate(p Policy) error {
+	if len(p.Sensors) == 0 {
+		return fmt.Errorf("policy declares no sensors")
+	}
+	seen := make(map[string]bool, len(p.Sensors))
+	for _, s := range p.Sensors {
+		if seen[s.Name] {
+			return fmt.Errorf("duplicate sensor name %q", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	for _, r := range p.Rules {
+		if r.Then.Actuator != "fan" && r.Then.Actuator != "cooling" {
+			return fmt.Errorf("rule %q: unknown actuator %q", r.Name, r.Then.Actuator)
+		}
+	}
+	return nil
+}
+
+// compiledRule bundles a Rule with its Go condition expression and a stable
+// index used to name its debounce/hysteresis state variables.
+type compiledRule struct {
+	Index       int
+	Rule        Rule
+	ConditionGo string
+}
+
+// compileExpr lowers an Expr tree to a Go boolean expression string,
+// referencing the per-sensor sample-window globals the template declares.
+func compileExpr(e Expr, sensors map[string]Sensor) (string, error) {
+	switch e.Kind {
+	case ExprAnd, ExprOr:
+		if len(e.Operands) == 0 {
+			return "", fmt.Errorf("%s expression has no operands", e.Kind)
+		}
+		op := " && "
+		if e.Kind == ExprOr {
+			op = " || "
+		}
+		parts := make([]string, len(e.Operands))
+		for i, child := range e.Operands {
+			c, err := compileExpr(child, sensors)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = "(" + c + ")"
+		}
+		return joinStrings(parts, op), nil
+
+	case ExprNot:
+		if len(e.Operands) != 1 {
+			return "", fmt.Errorf("not expression requires exactly one operand")
+		}
+		c, err := compileExpr(e.Operands[0], sensors)
+		if err != nil {
+			return "", err
+		}
+		return "!(" + c + ")", nil
+
+	case ExprCompare:
+		sensor, ok := sensors[e.Sensor]
+		if !ok {
+			return "", fmt.Errorf("unknown sensor %q", e.Sensor)
+		}
+		lhs, err := compileAggRef(sensor, e.Agg)
+		if err != nil {
+			return "", err
+		}
+		goOp, ok := goCompareOps[e.Op]
+		if !ok {
+			return "", fmt.Errorf("unknown comparison operator %q", e.Op)
+		}
+		return fmt.Sprintf("%s %s %s", lhs, goOp, formatFloat(e.Value)), nil
+
+	default:
+		return "", fmt.Errorf("unknown expression kind %q", e.Kind)
+	}
+}
+
+var goCompareOps = map[CompareOp]string{
+	OpGreaterThan: ">",
+	OpLessThan:    "<",
+	OpGreaterEq:   ">=",
+	OpLessEq:      "<=",
+	OpEqual:       "==",
+}
+
+// compileAggRef returns the Go expression that reads the latest sample or
+// rolling aggregate for sensor, depending on agg.
+func compileAggRef(sensor Sensor, agg AggFunc) (string, error) {
+	field := "window_" + sensor.Name
+	switch agg {
+	case AggNone:
+		return fmt.Sprintf("latest_%s", sensor.Name), nil
+	case AggMean:
+		if sensor.WindowSize <= 0 {
+			return "", fmt.Errorf("sensor %q: mean() requires window_size > 0", sensor.Name)
+		}
+		return fmt.Sprintf("%s.mean()", field), nil
+	case AggMax:
+		if sensor.WindowSize <= 0 {
+			return "", fmt.Errorf("sensor %q: max() requires window_size > 0", sensor.Name)
+		}
+		return fmt.Sprintf("%s.max()", field), nil
+	default:
+		return "", fmt.Errorf("sensor %q: unknown aggregate %q", sensor.Name, agg)
+	}
+}
+
+func joinStrings(parts []string, sep string) string {
+	out := parts[0]
+	for _, p := range parts[1:] {
+		out += sep + p
+	}
+	return out
+}
+
+func formatFloat(v float64) string {
+	return fmt.Sprintf("%g", v)
+}
+
+// guestTemplate renders a full TinyGo file. Each sensor with WindowSize > 0
+// gets a fixed-capacity ring buffer in guest linear memory (no heap
+// allocation, since TinyGo's WASI target keeps GC off the hot path);
+// sensors with WindowSize == 0 only keep their latest sample.
+var guestTemplate = template.Must(template.New("policy").Parse(`// Code generated by pkg/rules from a policy file. DO NOT EDIT.
+// Compile with: tinygo build -target=wasi -o cell.wasm policy_cell.go
+
+package main
+
+{{range .Sensors}}
+{{if gt .WindowSize 0}}
+type ring_{{.Name}} struct {
+	buf [{{.WindowSize}}]float32
+	n   int
+	pos int
+}
+
+func (r *ring_{{.Name}}) push(v float32) {
+	r.buf[r.pos] = v
+	r.pos = (r.pos + 1) % len(r.buf)
+	if r.n < len(r.buf) {
+		r.n++
+	}
+}
+
+func (r *ring_{{.Name}}) mean() float32 {
+	if r.n == 0 {
+		return 0
+	}
+	var sum float32
+	for i := 0; i < r.n; i++ {
+		sum += r.buf[i]
+	}
+	return sum / float32(r.n)
+}
+
+func (r *ring_{{.Name}}) max() float32 {
+	if r.n == 0 {
+		return 0
+	}
+	m := r.buf[0]
+	for i := 1; i < r.n; i++ {
+		if r.buf[i] > m {
+			m = r.buf[i]
+		}
+	}
+	return m
+}
+
+var window_{{.Name}} ring_{{.Name}}
+{{end}}
+var latest_{{.Name}} float32
+{{end}}
+
+{{range .Rules}}{{if or (gt .Rule.Debounce 0) (gt .Rule.Hysteresis 0)}}
+var rule_{{.Index}}_hits int   // consecutive samples where the condition held
+var rule_{{.Index}}_misses int // consecutive samples where it did not
+var rule_{{.Index}}_armed bool = true
+{{end}}{{end}}
+
+// process_sensor_data is generated from the policy's rule list, evaluated
+// in descending priority order. A lower-priority rule marked "override"
+// replaces an already-matched higher-priority rule's actuation rather than
+// being skipped.
+//export process_sensor_data
+func process_sensor_data(sensor_id uint32, value float32) uint32 {
+	switch sensor_id {
+{{range .Sensors}}	case {{.ID}}:
+		latest_{{.Name}} = value
+{{if gt .WindowSize 0}}		window_{{.Name}}.push(value)
+{{end}}{{end}}	}
+
+	result := uint32(0)
+	matched := false
+	var pending func() uint32 // the winning rule's actuation, run after all rules
+
+{{range .Rules}}	{
+		cond := {{.ConditionGo}}
+{{if or (gt .Rule.Debounce 0) (gt .Rule.Hysteresis 0)}}		if cond {
+			rule_{{.Index}}_hits++
+			rule_{{.Index}}_misses = 0
+		} else {
+			rule_{{.Index}}_misses++
+			rule_{{.Index}}_hits = 0
+			if rule_{{.Index}}_misses >= {{.Rule.Hysteresis}} {
+				rule_{{.Index}}_armed = true
+			}
+		}
+		fired := rule_{{.Index}}_armed && rule_{{.Index}}_hits >= {{.Rule.Debounce}} && rule_{{.Index}}_hits > 0
+{{else}}		fired := cond
+{{end}}		if fired {
+{{if not .Rule.Override}}			if !matched {
+{{end}}				switch "{{.Rule.Then.Actuator}}" {
+				case "fan":
+					pending = func() uint32 { return actuate_fan({{.Rule.Then.Power}}) }
+				case "cooling":
+					pending = func() uint32 { return actuate_cooling({{.Rule.Then.Power}}) }
+				}
+				matched = true
+{{if not .Rule.Override}}			}
+{{end}}{{if gt .Rule.Hysteresis 0}}			rule_{{.Index}}_armed = false
+{{end}}		}
+	}
+{{end}}
+	if pending != nil {
+		result = pending()
+	}
+	return result
+}
+
+// actuate_fan and actuate_cooling are host imports, not guest-exported
+// stubs: actuation always happens on the host's mediated ActuatorRegistry
+// (see pkg/wasmhost), so a rules-compiled cell is sandboxed the same way
+// as the hand-written one.
+
+//go:wasmimport env actuate_fan
+func actuate_fan(power uint32) uint32
+
+//go:wasmimport env actuate_cooling
+func actuate_cooling(power uint32) uint32
+
+func main() {}
+`))
@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCompileMatchesHandWrittenThresholds compiles a policy equivalent to
+// copilot.go's hand-written switch (CO2 > 1200 -> fan 80, temp > 35 ->
+// cooling 100) and checks the generated TinyGo source contains the
+// expected thresholds and actuator calls.
+func TestCompileMatchesHandWrittenThresholds(t *testing.T) {
+	p := Policy{
+		Sensors: []Sensor{
+			{ID: 0, Name: "co2"},
+			{ID: 1, Name: "temp"},
+		},
+		Rules: []Rule{
+			{
+				Name:     "co2_high",
+				Priority: 10,
+				Condition: Expr{
+					Kind: ExprCompare, Sensor: "co2", Op: OpGreaterThan, Value: 1200,
+				},
+				Then: Actuation{Actuator: "fan", Power: 80},
+			},
+			{
+				Name:     "temp_high",
+				Priority: 10,
+				Condition: Expr{
+					Kind: ExprCompare, Sensor: "temp", Op: OpGreaterThan, Value: 35,
+				},
+				Then: Actuation{Actuator: "cooling", Power: 100},
+			},
+		},
+	}
+
+	src, err := Compile(p)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	for _, want := range []string{
+		"//export process_sensor_data",
+		"latest_co2 > 1200",
+		"actuate_fan(80)",
+		"latest_temp > 35",
+		"actuate_cooling(100)",
+		"//go:wasmimport env actuate_fan",
+		"//go:wasmimport env actuate_cooling",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+
+	// actuate_fan/actuate_cooling must be host imports, not guest-exported
+	// stubs that short-circuit the host's mediated ActuatorRegistry.
+	for _, unwanted := range []string{"//export actuate_fan", "//export actuate_cooling"} {
+		if strings.Contains(string(src), unwanted) {
+			t.Errorf("generated source should not re-export %q as a guest stub:\n%s", unwanted, src)
+		}
+	}
+}
+
+func TestCompileRejectsUnknownActuator(t *testing.T) {
+	p := Policy{
+		Sensors: []Sensor{{ID: 0, Name: "co2"}},
+		Rules: []Rule{{
+			Name:      "bad",
+			Condition: Expr{Kind: ExprCompare, Sensor: "co2", Op: OpGreaterThan, Value: 1},
+			Then:      Actuation{Actuator: "heater", Power: 1},
+		}},
+	}
+	if _, err := Compile(p); err == nil {
+		t.Fatal("expected error for unknown actuator, got nil")
+	}
+}
+
+func TestCompileWithRollingAggregate(t *testing.T) {
+	p := Policy{
+		Sensors: []Sensor{{ID: 0, Name: "co2", WindowSize: 8}},
+		Rules: []Rule{{
+			Name:     "co2_mean_high",
+			Priority: 5,
+			Condition: Expr{
+				Kind: ExprCompare, Sensor: "co2", Agg: AggMean, Op: OpGreaterThan, Value: 900,
+			},
+			Then:     Actuation{Actuator: "fan", Power: 50},
+			Debounce: 3,
+		}},
+	}
+
+	src, err := Compile(p)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	for _, want := range []string{"window_co2.mean()", "ring_co2", "rule_0_hits"} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
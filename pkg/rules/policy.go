@@ -0,0 +1,101 @@
+// Package rules lets operators describe a cell's sensor-to-actuator
+// behavior as a declarative YAML/JSON policy instead of a hand-written Go
+// switch. The policy is compiled to an equivalent TinyGo source file
+// (Compile) that builds to the same WASM target the hand-written
+// process_sensor_data guest does, so a cell's behavior can ship OTA as a
+// policy file without rewriting and redeploying Go.
+package rules
+
+// Policy is the root document operators author. It is decoded directly
+// from YAML or JSON — the two are structurally identical for this schema.
+type Policy struct {
+	// Sensors declares every sensor_id the compiled cell understands, so
+	// the generator can size rolling-aggregate buffers up front.
+	Sensors []Sensor `yaml:"sensors" json:"sensors"`
+
+	// Rules are evaluated in Priority order (highest first); the first
+	// matching rule's actuation wins unless a lower-priority rule is
+	// marked Override, in which case it replaces rather than merely
+	// supplementing the higher-priority rule's decision.
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Sensor describes one physical input the compiled cell reads.
+type Sensor struct {
+	ID   uint32 `yaml:"id" json:"id"`
+	Name string `yaml:"name" json:"name"`
+
+	// WindowSize is the number of most-recent samples kept for this
+	// sensor's rolling aggregates (Agg expressions). Zero disables
+	// windowing and only the latest sample is available.
+	WindowSize int `yaml:"window_size,omitempty" json:"window_size,omitempty"`
+}
+
+// Rule pairs a boolean Condition with the Actuation to fire when it holds.
+type Rule struct {
+	Name      string    `yaml:"name" json:"name"`
+	Priority  int       `yaml:"priority" json:"priority"`
+	Override  bool      `yaml:"override,omitempty" json:"override,omitempty"`
+	Condition Expr      `yaml:"when" json:"when"`
+	Then      Actuation `yaml:"then" json:"then"`
+
+	// Debounce requires Condition to hold for this many consecutive
+	// samples before Then fires, and Hysteresis requires it to go false
+	// for this many consecutive samples before the rule may re-arm —
+	// avoiding actuator chatter right at a threshold.
+	Debounce   int `yaml:"debounce,omitempty" json:"debounce,omitempty"`
+	Hysteresis int `yaml:"hysteresis,omitempty" json:"hysteresis,omitempty"`
+}
+
+// Actuation is the effect a matching Rule has on the guest's hardware.
+type Actuation struct {
+	Actuator string `yaml:"actuator" json:"actuator"` // "fan" or "cooling"
+	Power    uint8  `yaml:"power" json:"power"`
+}
+
+// ExprKind distinguishes the node types an Expr tree can hold.
+type ExprKind string
+
+const (
+	ExprAnd     ExprKind = "and"
+	ExprOr      ExprKind = "or"
+	ExprNot     ExprKind = "not"
+	ExprCompare ExprKind = "compare"
+)
+
+// CompareOp is a comparison operator usable in a leaf Expr.
+type CompareOp string
+
+const (
+	OpGreaterThan CompareOp = ">"
+	OpLessThan    CompareOp = "<"
+	OpGreaterEq   CompareOp = ">="
+	OpLessEq      CompareOp = "<="
+	OpEqual       CompareOp = "=="
+)
+
+// AggFunc is a rolling aggregate over a sensor's sample window.
+type AggFunc string
+
+const (
+	AggNone AggFunc = ""     // use the latest sample directly
+	AggMean AggFunc = "mean" // rolling mean over the sensor's window
+	AggMax  AggFunc = "max"  // rolling max over the sensor's window
+)
+
+// Expr is one node of a rule's condition tree. Exactly one of the fields
+// appropriate to Kind is populated:
+//
+//   - and/or:     Operands
+//   - not:        Operands[0]
+//   - compare:    Sensor, Agg, Op, Value
+type Expr struct {
+	Kind ExprKind `yaml:"kind" json:"kind"`
+
+	Operands []Expr `yaml:"operands,omitempty" json:"operands,omitempty"`
+
+	Sensor string    `yaml:"sensor,omitempty" json:"sensor,omitempty"`
+	Agg    AggFunc   `yaml:"agg,omitempty" json:"agg,omitempty"`
+	Op     CompareOp `yaml:"op,omitempty" json:"op,omitempty"`
+	Value  float64   `yaml:"value,omitempty" json:"value,omitempty"`
+}
@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Phoenixai36/PhoenixDemiGod-System/pkg/wasmhost"
+)
+
+// Sample is one recorded sensor reading in a replay trace.
+type Sample struct {
+	SensorID uint32
+	Value    float32
+}
+
+// FiredActuation is one actuator call a simulated run observed, alongside the
+// trace index that triggered it.
+type FiredActuation struct {
+	SampleIndex int
+	SensorID    uint32
+	Actuator    string
+	Power       uint32
+}
+
+// recordingActuators implements wasmhost.ActuatorRegistry and simply logs
+// every call instead of touching hardware, so Simulate can report which
+// actuations a compiled policy would have fired against a recorded trace.
+type recordingActuators struct {
+	sampleIndex   *int
+	currentSensor *uint32
+	fired         *[]FiredActuation
+}
+
+func (r recordingActuators) ActuateFan(_ context.Context, power uint32) uint32 {
+	*r.fired = append(*r.fired, FiredActuation{SampleIndex: *r.sampleIndex, SensorID: *r.currentSensor, Actuator: "fan", Power: power})
+	return 1
+}
+
+func (r recordingActuators) ActuateCooling(_ context.Context, power uint32) uint32 {
+	*r.fired = append(*r.fired, FiredActuation{SampleIndex: *r.sampleIndex, SensorID: *r.currentSensor, Actuator: "cooling", Power: power})
+	return 1
+}
+
+// Simulate replays trace against wasmBytes (typically the output of
+// Compile, built to WASM) through the real wasmhost.Host runtime, so the
+// memory/deadline limits a production cell runs under also apply to the
+// simulated run.
+func Simulate(ctx context.Context, wasmBytes []byte, trace []Sample) ([]FiredActuation, error) {
+	var fired []FiredActuation
+	index := 0
+	var currentSensor uint32
+	reg := recordingActuators{sampleIndex: &index, currentSensor: &currentSensor, fired: &fired}
+
+	host, err := wasmhost.NewHost(ctx, wasmBytes, reg, wasmhost.DefaultHostConfig())
+	if err != nil {
+		return nil, fmt.Errorf("rules: loading compiled cell: %w", err)
+	}
+	defer host.Close(ctx)
+
+	for i, s := range trace {
+		index = i
+		currentSensor = s.SensorID
+		if _, err := host.ProcessSensorData(ctx, s.SensorID, s.Value); err != nil {
+			return fired, fmt.Errorf("rules: sample %d (sensor %d): %w", i, s.SensorID, err)
+		}
+	}
+	return fired, nil
+}
@@ -0,0 +1,36 @@
+package wasmhost
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// actuatorModuleName is the wasm import module under which actuate_fan and
+// actuate_cooling are registered, matching the `//export` names the TinyGo
+// guest currently uses for its own stubs.
+const actuatorModuleName = "env"
+
+// newActuatorHostModule registers actuate_fan and actuate_cooling as host
+// functions backed by reg, so the guest cell imports real actuation instead
+// of exporting placeholder stubs.
+func newActuatorHostModule(ctx context.Context, runtime wazero.Runtime, reg ActuatorRegistry) (api.Module, error) {
+	builder := runtime.NewHostModuleBuilder(actuatorModuleName)
+
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, power uint32) uint32 {
+			return reg.ActuateFan(ctx, power)
+		}).
+		Export("actuate_fan")
+
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, power uint32) uint32 {
+			return reg.ActuateCooling(ctx, power)
+		}).
+		Export("actuate_cooling")
+
+	registerTrapImports(builder)
+
+	return builder.Instantiate(ctx)
+}
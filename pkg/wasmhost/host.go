@@ -0,0 +1,243 @@
+// Package wasmhost embeds the wazero WebAssembly runtime on the SoC firmware
+// host. It loads the TinyGo guest cell (e.g. phase-1-prototype's copilot
+// module), wires the actuator imports to real hardware, and enforces the
+// resource limits a sandboxed cell must respect: a memory page cap and a
+// wall-clock deadline per invocation.
+//
+// wazero has no fuel/gas metering concept (that is a wasmtime/wasmer
+// feature) and does not expose per-instruction hooks publicly, so this
+// package does not attempt to bound the number of instructions a call may
+// execute — only its wall-clock time and its memory footprint. A cell stuck
+// in a tight loop is caught by Deadline; ErrFuelExhausted does not exist.
+//
+// wazero is pure Go (no CGO), which keeps the host cross-compilable for the
+// same embedded build targets as the rest of the firmware.
+package wasmhost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Errors returned by a guest invocation when a resource limit is hit. They
+// are typed so firmware callers can decide whether to retry, restart the
+// cell, or escalate to a safe-mode fallback without brick the device.
+var (
+	// ErrDeadline is returned when a call does not complete before its
+	// wall-clock deadline.
+	ErrDeadline = errors.New("wasmhost: guest cell missed its invocation deadline")
+
+	// ErrTrap is returned for any other guest-side trap (out-of-bounds
+	// memory access, unreachable, integer divide by zero, ...).
+	ErrTrap = errors.New("wasmhost: guest cell trapped")
+)
+
+// ActuatorRegistry mediates access to the physical hardware so that several
+// guest cells can share it safely. Implementations are expected to
+// serialize concurrent calls and apply whatever arbitration policy the
+// firmware needs (see CellManager for the multi-cell case).
+type ActuatorRegistry interface {
+	// ActuateFan drives the fan at the requested power (0-100) and returns
+	// 1 on success, 0 on failure, matching the guest ABI's uint32 result.
+	ActuateFan(ctx context.Context, power uint32) uint32
+
+	// ActuateCooling drives the cooling system at the requested power.
+	ActuateCooling(ctx context.Context, power uint32) uint32
+}
+
+// EngineKind selects which of wazero's two execution strategies a Host uses.
+type EngineKind int
+
+const (
+	// EngineAuto benchmarks the module once at load time and picks whichever
+	// engine finishes process_sensor_data fastest for the running hardware.
+	EngineAuto EngineKind = iota
+	// EngineCompiler forces wazero's ahead-of-time compiler, which trades
+	// startup latency for faster steady-state execution.
+	EngineCompiler
+	// EngineInterpreter forces wazero's interpreter, which starts faster and
+	// has a smaller memory footprint — useful on the most constrained SoCs.
+	EngineInterpreter
+)
+
+// HostConfig tunes the resource limits and engine selection for a Host.
+type HostConfig struct {
+	// Engine selects the wazero execution strategy. Defaults to EngineAuto.
+	Engine EngineKind
+
+	// MemoryPages caps the guest's linear memory, in 64KiB wasm pages.
+	MemoryPages uint32
+
+	// Deadline bounds the wall-clock time a single invocation may take
+	// before it is cancelled and ErrDeadline is returned.
+	Deadline time.Duration
+}
+
+// DefaultHostConfig returns the limits used when a caller does not tune
+// HostConfig itself: a single 64KiB page and a deadline generous enough for
+// slow sensor cells on constrained hardware.
+func DefaultHostConfig() HostConfig {
+	return HostConfig{
+		Engine:      EngineAuto,
+		MemoryPages: 1,
+		Deadline:    50 * time.Millisecond,
+	}
+}
+
+// Host loads a single compiled guest cell and exposes process_sensor_data to
+// firmware callers under the configured resource limits.
+type Host struct {
+	cfg       HostConfig
+	runtime   wazero.Runtime
+	module    wazero.CompiledModule
+	instance  api.Module
+	actuators ActuatorRegistry
+}
+
+// NewHost compiles wasmBytes (a TinyGo-built `-target=wasi` module) and
+// instantiates it with actuate_fan/actuate_cooling wired to reg rather than
+// left as guest-exported stubs, so actuation always happens on the host and
+// the guest is a pure policy module.
+func NewHost(ctx context.Context, wasmBytes []byte, reg ActuatorRegistry, cfg HostConfig) (*Host, error) {
+	engine := cfg.Engine
+	if engine == EngineAuto {
+		var err error
+		engine, err = selectEngine(ctx, wasmBytes, cfg.MemoryPages)
+		if err != nil {
+			return nil, fmt.Errorf("wasmhost: engine selection: %w", err)
+		}
+	}
+	rtCfg := runtimeConfigFor(engine).WithMemoryLimitPages(cfg.MemoryPages)
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, rtCfg)
+
+	if _, err := newActuatorHostModule(ctx, runtime, reg); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("wasmhost: registering actuator imports: %w", err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("wasmhost: compiling guest cell: %w", err)
+	}
+
+	modCfg := wazero.NewModuleConfig().WithName("")
+	instance, err := runtime.InstantiateModule(ctx, compiled, modCfg)
+	if err != nil {
+		compiled.Close(ctx)
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("wasmhost: instantiating guest cell: %w", err)
+	}
+
+	return &Host{cfg: cfg, runtime: runtime, module: compiled, instance: instance, actuators: reg}, nil
+}
+
+// Close releases the wazero runtime and all modules it owns.
+func (h *Host) Close(ctx context.Context) error {
+	return h.runtime.Close(ctx)
+}
+
+// ProcessSensorData invokes the guest's process_sensor_data export under the
+// Host's memory and deadline limits, translating any wazero-level failure
+// into ErrDeadline or ErrTrap.
+func (h *Host) ProcessSensorData(ctx context.Context, sensorID uint32, value float32) (result uint32, err error) {
+	fn := h.instance.ExportedFunction("process_sensor_data")
+	if fn == nil {
+		return 0, fmt.Errorf("wasmhost: guest cell does not export process_sensor_data")
+	}
+
+	// proc_exit/abort unwind the current invocation via panic (see trap.go)
+	// rather than returning a sentinel, because wazero's compiler may place
+	// unreachable code right after such an import call.
+	defer func() {
+		if trapErr := recoverTrap(); trapErr != nil {
+			err = trapErr
+		}
+	}()
+
+	callCtx := ctx
+	var cancel context.CancelFunc
+	if h.cfg.Deadline > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, h.cfg.Deadline)
+		defer cancel()
+	}
+
+	results, callErr := fn.Call(callCtx, api.EncodeU32(sensorID), uint64(api.EncodeF32(value)))
+	if callErr != nil {
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return 0, ErrDeadline
+		}
+		return 0, fmt.Errorf("%w: %v", ErrTrap, callErr)
+	}
+	return api.DecodeU32(results[0]), nil
+}
+
+// runtimeConfigFor returns the wazero RuntimeConfig matching engine.
+// wazero fixes its engine at RuntimeConfig construction time
+// (NewRuntimeConfigCompiler vs NewRuntimeConfigInterpreter) — there is no
+// way to toggle it afterwards, so callers must pick the base config before
+// a Runtime is created.
+func runtimeConfigFor(engine EngineKind) wazero.RuntimeConfig {
+	if engine == EngineInterpreter {
+		return wazero.NewRuntimeConfigInterpreter()
+	}
+	return wazero.NewRuntimeConfigCompiler()
+}
+
+// selectEngine compiles wasmBytes once under each engine and keeps whichever
+// finishes a representative process_sensor_data call faster, per HostConfig's
+// benchmark-driven compiler-vs-interpreter selection.
+func selectEngine(ctx context.Context, wasmBytes []byte, memoryPages uint32) (EngineKind, error) {
+	bench := func(engine EngineKind) (time.Duration, error) {
+		rtCfg := runtimeConfigFor(engine).WithMemoryLimitPages(memoryPages)
+		rt := wazero.NewRuntimeWithConfig(ctx, rtCfg)
+		defer rt.Close(ctx)
+		if _, err := newActuatorHostModule(ctx, rt, noopActuators{}); err != nil {
+			return 0, err
+		}
+		compiled, err := rt.CompileModule(ctx, wasmBytes)
+		if err != nil {
+			return 0, err
+		}
+		defer compiled.Close(ctx)
+		mod, err := rt.InstantiateModule(ctx, compiled, wazero.NewModuleConfig())
+		if err != nil {
+			return 0, err
+		}
+		fn := mod.ExportedFunction("process_sensor_data")
+		if fn == nil {
+			return 0, fmt.Errorf("missing process_sensor_data export")
+		}
+		start := time.Now()
+		if _, err := fn.Call(ctx, api.EncodeU32(0), uint64(api.EncodeF32(0))); err != nil {
+			return 0, err
+		}
+		return time.Since(start), nil
+	}
+
+	compilerTime, err := bench(EngineCompiler)
+	if err != nil {
+		return EngineInterpreter, nil
+	}
+	interpTime, err := bench(EngineInterpreter)
+	if err != nil {
+		return EngineCompiler, nil
+	}
+	if interpTime < compilerTime {
+		return EngineInterpreter, nil
+	}
+	return EngineCompiler, nil
+}
+
+// noopActuators discards actuation requests; it exists so selectEngine can
+// instantiate a scratch copy of the module without touching real hardware.
+type noopActuators struct{}
+
+func (noopActuators) ActuateFan(context.Context, uint32) uint32     { return 1 }
+func (noopActuators) ActuateCooling(context.Context, uint32) uint32 { return 1 }
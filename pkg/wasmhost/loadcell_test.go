@@ -0,0 +1,188 @@
+package wasmhost
+
+import (
+	"context"
+	"testing"
+)
+
+// uleb128 encodes n as unsigned LEB128, the variable-length integer format
+// every size/count field in the wasm binary format uses.
+func uleb128(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+// sleb128 encodes v as signed LEB128, used for i64.const immediates.
+func sleb128(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		done := (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0)
+		if !done {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if done {
+			return out
+		}
+	}
+}
+
+func wName(s string) []byte { return append(uleb128(len(s)), []byte(s)...) }
+
+func wVec(items ...[]byte) []byte {
+	out := uleb128(len(items))
+	for _, it := range items {
+		out = append(out, it...)
+	}
+	return out
+}
+
+func wSection(id byte, content []byte) []byte {
+	out := []byte{id}
+	out = append(out, uleb128(len(content))...)
+	return append(out, content...)
+}
+
+func wFuncType(params, results []byte) []byte {
+	ft := []byte{0x60}
+	ft = append(ft, uleb128(len(params))...)
+	ft = append(ft, params...)
+	ft = append(ft, uleb128(len(results))...)
+	ft = append(ft, results...)
+	return ft
+}
+
+func wCodeEntry(body []byte) []byte {
+	entry := append([]byte{0x00}, body...) // 0 local-decl groups
+	return append(uleb128(len(entry)), entry...)
+}
+
+const (
+	valI32 = 0x7f
+	valF32 = 0x7d
+	valI64 = 0x7e
+)
+
+// buildCellWithManifest hand-assembles (no TinyGo toolchain needed) the
+// smallest possible wasm module exporting both process_sensor_data(i32,
+// f32)->i32 (always returns 1) and cell_manifest()->i64 (always returns
+// manifestLane), so LoadCell's auto-detection path can be exercised
+// end-to-end.
+func buildCellWithManifest(manifestLane int64) []byte {
+	typeSec := wSection(1, wVec(
+		wFuncType([]byte{valI32, valF32}, []byte{valI32}),
+		wFuncType(nil, []byte{valI64}),
+	))
+	funcSec := wSection(3, wVec([]byte{0x00}, []byte{0x01}))
+	exportSec := wSection(7, wVec(
+		append(wName("process_sensor_data"), 0x00, 0x00),
+		append(wName("cell_manifest"), 0x00, 0x01),
+	))
+	body1 := []byte{0x41, 0x01, 0x0b} // i32.const 1; end
+	body2 := append([]byte{0x42}, append(sleb128(manifestLane), 0x0b)...)
+	codeSec := wSection(10, wVec(wCodeEntry(body1), wCodeEntry(body2)))
+
+	out := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00} // \0asm, version 1
+	out = append(out, typeSec...)
+	out = append(out, funcSec...)
+	out = append(out, exportSec...)
+	out = append(out, codeSec...)
+	return out
+}
+
+// buildTrappingCell is like buildCellWithManifest but has no cell_manifest
+// export, and its process_sensor_data unconditionally executes
+// `unreachable`, so every Dispatch to it traps.
+func buildTrappingCell() []byte {
+	typeSec := wSection(1, wVec(wFuncType([]byte{valI32, valF32}, []byte{valI32})))
+	funcSec := wSection(3, wVec([]byte{0x00}))
+	exportSec := wSection(7, wVec(append(wName("process_sensor_data"), 0x00, 0x00)))
+	codeSec := wSection(10, wVec(wCodeEntry([]byte{0x00, 0x0b}))) // unreachable; end
+
+	out := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	out = append(out, typeSec...)
+	out = append(out, funcSec...)
+	out = append(out, exportSec...)
+	out = append(out, codeSec...)
+	return out
+}
+
+func TestLoadCellAutoDetectsManifestFromExport(t *testing.T) {
+	ctx := context.Background()
+	mgr := NewCellManager(noopActuators{}, nil, CellSupervisionConfig{})
+
+	wasmBytes := buildCellWithManifest(2) // lane 2 -> sensor_id 1, no actuators
+	if err := mgr.LoadCell(ctx, "auto", wasmBytes, CellManifest{}, DefaultHostConfig()); err != nil {
+		t.Fatalf("LoadCell: %v", err)
+	}
+
+	mgr.mu.RLock()
+	cell := mgr.cells["auto"]
+	mgr.mu.RUnlock()
+	if cell == nil {
+		t.Fatal("cell not registered")
+	}
+	if !cell.manifest.subscribesTo(1) {
+		t.Fatalf("manifest = %+v, want a subscription to sensor 1 auto-detected from cell_manifest", cell.manifest)
+	}
+}
+
+func TestLoadCellKeepsCallerManifestWhenNotZero(t *testing.T) {
+	ctx := context.Background()
+	mgr := NewCellManager(noopActuators{}, nil, CellSupervisionConfig{})
+
+	wasmBytes := buildCellWithManifest(2) // would auto-detect sensor 1 if given the chance
+	explicit := CellManifest{SensorIDs: []uint32{9}}
+	if err := mgr.LoadCell(ctx, "explicit", wasmBytes, explicit, DefaultHostConfig()); err != nil {
+		t.Fatalf("LoadCell: %v", err)
+	}
+
+	mgr.mu.RLock()
+	cell := mgr.cells["explicit"]
+	mgr.mu.RUnlock()
+	if !cell.manifest.subscribesTo(9) || cell.manifest.subscribesTo(1) {
+		t.Fatalf("manifest = %+v, want the caller-supplied manifest left untouched", cell.manifest)
+	}
+}
+
+// TestDispatchRestartsCellAfterConsecutiveTraps checks that Dispatch feeds
+// each invocation's result into the cell's Supervisor and that a cell
+// trapping MaxConsecutiveTraps times in a row gets restarted.
+func TestDispatchRestartsCellAfterConsecutiveTraps(t *testing.T) {
+	ctx := context.Background()
+	var faults []FaultEvent
+	mgr := NewCellManager(noopActuators{}, nil, CellSupervisionConfig{
+		MaxConsecutiveTraps: 2,
+		OnFault:             func(evt FaultEvent) { faults = append(faults, evt) },
+	})
+
+	wasmBytes := buildTrappingCell()
+	if err := mgr.LoadCell(ctx, "flaky", wasmBytes, CellManifest{SensorIDs: []uint32{0}}, DefaultHostConfig()); err != nil {
+		t.Fatalf("LoadCell: %v", err)
+	}
+
+	// Dispatch's WaitGroup waits for each per-cell goroutine, and
+	// RecordResult runs synchronously before that goroutine calls
+	// wg.Done, so faults is fully populated by the time Dispatch returns.
+	mgr.Dispatch(ctx, 0, 1.0)
+	mgr.Dispatch(ctx, 0, 1.0)
+
+	if len(faults) != 2 {
+		t.Fatalf("len(faults) = %d, want 2", len(faults))
+	}
+	if !faults[1].Restarted {
+		t.Fatalf("faults[1].Restarted = false, want true after %d consecutive traps", faults[1].ConsecutiveN)
+	}
+}
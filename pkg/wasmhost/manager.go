@@ -0,0 +1,389 @@
+package wasmhost
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CellManifest declares which sensors a cell subscribes to and which
+// actuators it is permitted to drive. A cell reports its manifest by an
+// exported cell_manifest function — see ManifestFromExport. Custom wasm
+// sections are not read.
+type CellManifest struct {
+	SensorIDs        []uint32
+	AllowedActuators []string // "fan", "cooling"
+}
+
+func (m CellManifest) subscribesTo(sensorID uint32) bool {
+	for _, id := range m.SensorIDs {
+		if id == sensorID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m CellManifest) mayActuate(actuator string) bool {
+	for _, a := range m.AllowedActuators {
+		if a == actuator {
+			return true
+		}
+	}
+	return false
+}
+
+// ArbitrationPolicy resolves conflicting actuator requests from multiple
+// cells reacting to the same sensor reading into the single request that
+// is actually sent to hardware.
+type ArbitrationPolicy interface {
+	Arbitrate(requests []ActuationRequest) ActuationRequest
+}
+
+// ActuationRequest is one cell's vote for how an actuator should be driven.
+type ActuationRequest struct {
+	CellName string
+	Actuator string
+	Power    uint32
+	Priority int // only meaningful to PriorityWinsArbitration
+}
+
+// MaxWinsArbitration always drives the actuator at the highest requested
+// power, which is the conservative choice for safety actuators like
+// cooling where under-driving is the risk.
+type MaxWinsArbitration struct{}
+
+func (MaxWinsArbitration) Arbitrate(requests []ActuationRequest) ActuationRequest {
+	winner := requests[0]
+	for _, r := range requests[1:] {
+		if r.Power > winner.Power {
+			winner = r
+		}
+	}
+	return winner
+}
+
+// PriorityWinsArbitration drives the actuator at the request from the
+// cell with the highest Priority, breaking ties by the first cell seen.
+type PriorityWinsArbitration struct{}
+
+func (PriorityWinsArbitration) Arbitrate(requests []ActuationRequest) ActuationRequest {
+	winner := requests[0]
+	for _, r := range requests[1:] {
+		if r.Priority > winner.Priority {
+			winner = r
+		}
+	}
+	return winner
+}
+
+// LastWriterWinsArbitration drives the actuator at whichever request was
+// recorded last. CellManager.Dispatch runs cells concurrently and records
+// requests as they arrive, so that order is not the order cells were
+// registered in.
+type LastWriterWinsArbitration struct{}
+
+func (LastWriterWinsArbitration) Arbitrate(requests []ActuationRequest) ActuationRequest {
+	return requests[len(requests)-1]
+}
+
+// CellMetrics are the Prometheus-style counters CellManager keeps per cell.
+// They are plain atomics rather than a client_golang dependency, so
+// firmware can export them however it already exposes metrics (the SoC
+// telemetry bus, a /metrics endpoint, ...).
+type CellMetrics struct {
+	Invocations      atomic.Uint64
+	Traps            atomic.Uint64
+	DeniedActuations atomic.Uint64
+}
+
+// CellSupervisionConfig tunes how CellManager restarts a cell that traps
+// repeatedly. See Supervisor for the consecutive-trap/backoff semantics;
+// CellManager keeps one Supervisor per loaded cell so one faulty cell's
+// trap streak cannot trigger another cell's restart.
+type CellSupervisionConfig struct {
+	// MaxConsecutiveTraps restarts a cell after this many back-to-back
+	// ProcessSensorData failures.
+	MaxConsecutiveTraps int
+	// BaseBackoff and MaxBackoff bound the exponential wait between restart
+	// attempts, as in Supervisor.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// OnFault, if set, is invoked with every FaultEvent so firmware can
+	// forward it to the SoC telemetry bus.
+	OnFault func(FaultEvent)
+}
+
+// DefaultCellSupervisionConfig restarts a cell after 3 consecutive traps,
+// waiting 100ms for the first restart and doubling up to a 5s cap for a
+// persistently faulty cell.
+func DefaultCellSupervisionConfig() CellSupervisionConfig {
+	return CellSupervisionConfig{
+		MaxConsecutiveTraps: 3,
+		BaseBackoff:         100 * time.Millisecond,
+		MaxBackoff:          5 * time.Second,
+	}
+}
+
+// registeredCell is a cell tracked by CellManager, along with its own Host,
+// manifest and metrics. host is guarded by its own mutex (rather than
+// CellManager's) because Dispatch reads it from concurrent per-cell
+// goroutines while a restart triggered by supervisor may replace it.
+type registeredCell struct {
+	name      string
+	manifest  CellManifest
+	metrics   *CellMetrics
+	wasmBytes []byte
+	cfg       HostConfig
+
+	supervisor *Supervisor
+
+	mu   sync.RWMutex
+	host *Host
+}
+
+func (c *registeredCell) processSensorData(ctx context.Context, sensorID uint32, value float32) (uint32, error) {
+	c.mu.RLock()
+	host := c.host
+	c.mu.RUnlock()
+	return host.ProcessSensorData(ctx, sensorID, value)
+}
+
+// CellManager loads and runs several WASM cells concurrently, routing each
+// physical sensor reading only to the cells subscribed to it and
+// arbitrating between any conflicting actuator requests they produce.
+//
+// This turns any single cell — including copilot.go's hand-written one —
+// into one of many hot-swappable cells rather than the whole control
+// program.
+type CellManager struct {
+	mu          sync.RWMutex
+	cells       map[string]*registeredCell
+	arbitration ArbitrationPolicy
+	reg         ActuatorRegistry
+	supervision CellSupervisionConfig
+}
+
+// NewCellManager returns a manager that dispatches real actuation through
+// reg once arbitration has picked a winning request, using policy to
+// resolve conflicts between cells and supervision to decide when a cell
+// that keeps trapping gets restarted. A zero-value CellSupervisionConfig
+// (MaxConsecutiveTraps <= 0) is replaced with DefaultCellSupervisionConfig.
+func NewCellManager(reg ActuatorRegistry, policy ArbitrationPolicy, supervision CellSupervisionConfig) *CellManager {
+	if policy == nil {
+		policy = MaxWinsArbitration{}
+	}
+	if supervision.MaxConsecutiveTraps <= 0 {
+		supervision = DefaultCellSupervisionConfig()
+	}
+	return &CellManager{cells: make(map[string]*registeredCell), arbitration: policy, reg: reg, supervision: supervision}
+}
+
+// LoadCell compiles and instantiates a new cell under name, subscribing it
+// to manifest.SensorIDs. If manifest is the zero value (no sensors, no
+// actuators declared), LoadCell tries to self-declare it by calling the
+// cell's own exported cell_manifest function first (see
+// ManifestFromExport) — a caller that really does mean "no subscriptions"
+// gets that same empty manifest back when the cell has no such export.
+// Actuator calls the cell attempts outside manifest.AllowedActuators are
+// denied and counted rather than forwarded to reg.
+func (m *CellManager) LoadCell(ctx context.Context, name string, wasmBytes []byte, manifest CellManifest, cfg HostConfig) error {
+	if len(manifest.SensorIDs) == 0 && len(manifest.AllowedActuators) == 0 {
+		if detected, err := ManifestFromExport(ctx, wasmBytes); err == nil {
+			manifest = detected
+		}
+	}
+
+	metrics := &CellMetrics{}
+	host, err := NewHost(ctx, wasmBytes, newMediatedActuators(name, manifest, metrics), cfg)
+	if err != nil {
+		return fmt.Errorf("wasmhost: loading cell %q: %w", name, err)
+	}
+
+	cell := &registeredCell{
+		name:      name,
+		manifest:  manifest,
+		metrics:   metrics,
+		wasmBytes: wasmBytes,
+		cfg:       cfg,
+		host:      host,
+	}
+	cell.supervisor = NewSupervisor(m.supervision.MaxConsecutiveTraps, m.supervision.BaseBackoff, m.supervision.MaxBackoff, m.supervision.OnFault)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.cells[name]; exists {
+		host.Close(ctx)
+		return fmt.Errorf("wasmhost: cell %q already loaded", name)
+	}
+	m.cells[name] = cell
+	return nil
+}
+
+// restartCell recompiles and re-instantiates cell from the wasmBytes/cfg it
+// was loaded with, swapping it in for the trapping instance. It is the
+// restart callback CellManager.Dispatch hands to Supervisor.RecordResult.
+func (m *CellManager) restartCell(ctx context.Context, cell *registeredCell) error {
+	newHost, err := NewHost(ctx, cell.wasmBytes, newMediatedActuators(cell.name, cell.manifest, cell.metrics), cell.cfg)
+	if err != nil {
+		return fmt.Errorf("wasmhost: restarting cell %q: %w", cell.name, err)
+	}
+	cell.mu.Lock()
+	old := cell.host
+	cell.host = newHost
+	cell.mu.Unlock()
+	return old.Close(ctx)
+}
+
+// UnloadCell closes and removes a previously loaded cell.
+func (m *CellManager) UnloadCell(ctx context.Context, name string) error {
+	m.mu.Lock()
+	cell, ok := m.cells[name]
+	if ok {
+		delete(m.cells, name)
+	}
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("wasmhost: cell %q not loaded", name)
+	}
+	cell.mu.RLock()
+	host := cell.host
+	cell.mu.RUnlock()
+	return host.Close(ctx)
+}
+
+// Metrics returns the counters for a loaded cell, or nil if no cell with
+// that name is currently loaded.
+func (m *CellManager) Metrics(name string) *CellMetrics {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if cell, ok := m.cells[name]; ok {
+		return cell.metrics
+	}
+	return nil
+}
+
+// Dispatch delivers one sensor reading to every cell subscribed to
+// sensorID in parallel, merges any conflicting actuator requests those
+// cells produced through the manager's ArbitrationPolicy, and applies the
+// single winning request per actuator to the shared hardware registry.
+// Errors from individual cells are counted in that cell's metrics rather
+// than failing the whole dispatch, since one faulty cell must not block
+// readings reaching the others.
+func (m *CellManager) Dispatch(ctx context.Context, sensorID uint32, value float32) {
+	m.mu.RLock()
+	var interested []*registeredCell
+	for _, cell := range m.cells {
+		if cell.manifest.subscribesTo(sensorID) {
+			interested = append(interested, cell)
+		}
+	}
+	m.mu.RUnlock()
+
+	var collected collectedRequests
+	callCtx := context.WithValue(ctx, collectedRequestsKey{}, &collected)
+
+	var wg sync.WaitGroup
+	wg.Add(len(interested))
+	for _, cell := range interested {
+		go func(cell *registeredCell) {
+			defer wg.Done()
+			cell.metrics.Invocations.Add(1)
+			_, err := cell.processSensorData(callCtx, sensorID, value)
+			if err != nil {
+				cell.metrics.Traps.Add(1)
+			}
+			cell.supervisor.RecordResult(cell.name, err, func(attempt int) error {
+				return m.restartCell(ctx, cell)
+			})
+		}(cell)
+	}
+	wg.Wait()
+
+	for _, requests := range collected.byActuator() {
+		winner := m.arbitration.Arbitrate(requests)
+		m.applyActuation(ctx, winner)
+	}
+}
+
+func (m *CellManager) applyActuation(ctx context.Context, req ActuationRequest) {
+	switch req.Actuator {
+	case "fan":
+		m.reg.ActuateFan(ctx, req.Power)
+	case "cooling":
+		m.reg.ActuateCooling(ctx, req.Power)
+	}
+}
+
+// collectedRequestsKey is the context key CellManager.Dispatch uses to hand
+// each cell invocation a pointer to the in-flight collectedRequests, since
+// a cell's Host (and its mediatedActuators) is fixed at LoadCell time and
+// cannot be rebuilt per Dispatch call.
+type collectedRequestsKey struct{}
+
+// collectedRequests gathers ActuationRequests from concurrently dispatched
+// cells under a single mutex, then groups them by actuator for arbitration.
+type collectedRequests struct {
+	mu       sync.Mutex
+	requests []ActuationRequest
+}
+
+func (c *collectedRequests) add(req ActuationRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+}
+
+func (c *collectedRequests) byActuator() map[string][]ActuationRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string][]ActuationRequest)
+	for _, r := range c.requests {
+		out[r.Actuator] = append(out[r.Actuator], r)
+	}
+	return out
+}
+
+// mediatedActuators wraps a cell's actuator imports so a call is checked
+// against the cell's manifest and, if allowed, recorded into the
+// collectedRequests CellManager.Dispatch stashed in ctx for this
+// invocation — rather than forwarded straight to hardware, since several
+// cells may react to the same reading and need to be arbitrated first.
+type mediatedActuators struct {
+	name     string
+	manifest CellManifest
+	metrics  *CellMetrics
+}
+
+// newMediatedActuators builds the ActuatorRegistry a cell's Host is wired
+// to, shared by LoadCell and restartCell so a restarted cell keeps the same
+// manifest and metrics it was loaded with.
+func newMediatedActuators(name string, manifest CellManifest, metrics *CellMetrics) *mediatedActuators {
+	return &mediatedActuators{name: name, manifest: manifest, metrics: metrics}
+}
+
+func (a *mediatedActuators) ActuateFan(ctx context.Context, power uint32) uint32 {
+	if !a.manifest.mayActuate("fan") {
+		a.metrics.DeniedActuations.Add(1)
+		return 0
+	}
+	a.record(ctx, ActuationRequest{CellName: a.name, Actuator: "fan", Power: power})
+	return 1
+}
+
+func (a *mediatedActuators) ActuateCooling(ctx context.Context, power uint32) uint32 {
+	if !a.manifest.mayActuate("cooling") {
+		a.metrics.DeniedActuations.Add(1)
+		return 0
+	}
+	a.record(ctx, ActuationRequest{CellName: a.name, Actuator: "cooling", Power: power})
+	return 1
+}
+
+func (a *mediatedActuators) record(ctx context.Context, req ActuationRequest) {
+	if collected, ok := ctx.Value(collectedRequestsKey{}).(*collectedRequests); ok {
+		collected.add(req)
+	}
+}
@@ -0,0 +1,46 @@
+package wasmhost
+
+import "testing"
+
+func TestMaxWinsArbitration(t *testing.T) {
+	requests := []ActuationRequest{
+		{CellName: "co2-cell", Actuator: "fan", Power: 40},
+		{CellName: "override-cell", Actuator: "fan", Power: 80},
+	}
+	got := (MaxWinsArbitration{}).Arbitrate(requests)
+	if got.Power != 80 || got.CellName != "override-cell" {
+		t.Fatalf("got %+v, want override-cell at power 80", got)
+	}
+}
+
+func TestPriorityWinsArbitration(t *testing.T) {
+	requests := []ActuationRequest{
+		{CellName: "low", Actuator: "cooling", Power: 100, Priority: 1},
+		{CellName: "high", Actuator: "cooling", Power: 10, Priority: 5},
+	}
+	got := (PriorityWinsArbitration{}).Arbitrate(requests)
+	if got.CellName != "high" {
+		t.Fatalf("got %+v, want cell \"high\" to win on priority", got)
+	}
+}
+
+func TestLastWriterWinsArbitration(t *testing.T) {
+	requests := []ActuationRequest{
+		{CellName: "first", Actuator: "fan", Power: 10},
+		{CellName: "second", Actuator: "fan", Power: 20},
+	}
+	got := (LastWriterWinsArbitration{}).Arbitrate(requests)
+	if got.CellName != "second" {
+		t.Fatalf("got %+v, want the last-dispatched cell to win", got)
+	}
+}
+
+func TestCellManifestSubscribesTo(t *testing.T) {
+	m := CellManifest{SensorIDs: []uint32{0, 2}}
+	if !m.subscribesTo(0) || !m.subscribesTo(2) {
+		t.Fatal("expected manifest to subscribe to declared sensor ids")
+	}
+	if m.subscribesTo(1) {
+		t.Fatal("manifest should not subscribe to an undeclared sensor id")
+	}
+}
@@ -0,0 +1,72 @@
+package wasmhost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// ManifestFromExport instantiates wasmBytes in a scratch runtime and reads
+// its manifest from an exported cell_manifest function. It is the only
+// way CellManager detects a cell's manifest; custom wasm sections are not
+// read.
+//
+// cell_manifest is expected to return a sensor_id/actuator-bitmask pair
+// packed into each result lane, terminated by a lane value of exactly
+// zero, per the guest ABI documented on decodeManifest.
+func ManifestFromExport(ctx context.Context, wasmBytes []byte) (CellManifest, error) {
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	if _, err := newActuatorHostModule(ctx, runtime, noopActuators{}); err != nil {
+		return CellManifest{}, fmt.Errorf("wasmhost: scratch actuator imports: %w", err)
+	}
+
+	mod, err := runtime.Instantiate(ctx, wasmBytes)
+	if err != nil {
+		return CellManifest{}, fmt.Errorf("wasmhost: instantiating for manifest read: %w", err)
+	}
+
+	fn := mod.ExportedFunction("cell_manifest")
+	if fn == nil {
+		return CellManifest{}, fmt.Errorf("wasmhost: guest cell exports no cell_manifest function")
+	}
+
+	results, err := fn.Call(ctx)
+	if err != nil {
+		return CellManifest{}, fmt.Errorf("wasmhost: calling cell_manifest: %w", err)
+	}
+
+	return decodeManifest(results)
+}
+
+// decodeManifest unpacks cell_manifest's raw i64 results into a
+// CellManifest. Each result lane's low 32 bits hold (sensor_id + 1), and
+// the high 32 bits are an actuator bitmask (bit 0 = fan, bit 1 = cooling).
+// Lanes are biased by 1 specifically so a lane value of exactly zero is
+// unambiguous padding/terminator and decoding can stop there — sensor_id 0
+// (CO2, see phase-1-prototype/firmware/copilot.go) is a real subscription
+// and must not be mistaken for the terminator a fixed-arity cell_manifest
+// export may pad its unused trailing lanes with.
+func decodeManifest(results []uint64) (CellManifest, error) {
+	var m CellManifest
+	seenActuators := make(map[string]bool)
+	for _, lane := range results {
+		if lane == 0 {
+			break
+		}
+		sensorID := uint32(lane) - 1
+		actuatorBits := uint32(lane >> 32)
+		m.SensorIDs = append(m.SensorIDs, sensorID)
+		if actuatorBits&0x1 != 0 && !seenActuators["fan"] {
+			m.AllowedActuators = append(m.AllowedActuators, "fan")
+			seenActuators["fan"] = true
+		}
+		if actuatorBits&0x2 != 0 && !seenActuators["cooling"] {
+			m.AllowedActuators = append(m.AllowedActuators, "cooling")
+			seenActuators["cooling"] = true
+		}
+	}
+	return m, nil
+}
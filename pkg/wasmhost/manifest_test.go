@@ -0,0 +1,47 @@
+package wasmhost
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeManifestStopsAtTerminatorLane(t *testing.T) {
+	results := []uint64{
+		1,               // sensor_id 0 (CO2), no actuators
+		2 | (0x1 << 32), // sensor_id 1, fan
+		3 | (0x2 << 32), // sensor_id 2, cooling
+		0,               // terminator/padding
+		4 | (0x3 << 32), // would be sensor_id 3 — must be ignored, past terminator
+	}
+
+	m, err := decodeManifest(results)
+	if err != nil {
+		t.Fatalf("decodeManifest: %v", err)
+	}
+	if want := []uint32{0, 1, 2}; !reflect.DeepEqual(m.SensorIDs, want) {
+		t.Fatalf("SensorIDs = %v, want %v", m.SensorIDs, want)
+	}
+	if want := []string{"fan", "cooling"}; !reflect.DeepEqual(m.AllowedActuators, want) {
+		t.Fatalf("AllowedActuators = %v, want %v", m.AllowedActuators, want)
+	}
+}
+
+func TestDecodeManifestSensorZeroNotMistakenForTerminator(t *testing.T) {
+	m, err := decodeManifest([]uint64{1})
+	if err != nil {
+		t.Fatalf("decodeManifest: %v", err)
+	}
+	if !m.subscribesTo(0) {
+		t.Fatal("expected a subscription to sensor_id 0 (CO2), got none")
+	}
+}
+
+func TestDecodeManifestEmpty(t *testing.T) {
+	m, err := decodeManifest(nil)
+	if err != nil {
+		t.Fatalf("decodeManifest: %v", err)
+	}
+	if len(m.SensorIDs) != 0 || len(m.AllowedActuators) != 0 {
+		t.Fatalf("decodeManifest(nil) = %+v, want empty manifest", m)
+	}
+}
@@ -0,0 +1,178 @@
+package wasmhost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// ExitError is returned when the guest cell calls the WASI-style proc_exit
+// import instead of returning normally from process_sensor_data.
+type ExitError struct {
+	Code uint32
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("wasmhost: guest cell exited with code %d", e.Code)
+}
+
+// AbortError is returned when the guest cell calls abort (emitted by TinyGo
+// for a Go panic or a failed bounds check) instead of returning normally.
+type AbortError struct {
+	Reason string
+	File   string
+	Line   uint32
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("wasmhost: guest cell aborted at %s:%d: %s", e.File, e.Line, e.Reason)
+}
+
+// cellExit and cellAbort carry ExitError/AbortError across the wazero call
+// boundary. wazero's compiler inserts an unreachable instruction after a
+// host import that never returns, so the host function itself must panic
+// rather than return a sentinel value — otherwise the compiled code after
+// the call is undefined and may execute anyway.
+type cellExit struct{ err *ExitError }
+type cellAbort struct{ err *AbortError }
+
+// registerTrapImports adds proc_exit and abort to the same "env" host
+// module as the actuator imports, so a single HostModuleBuilder instance
+// covers the guest's full import set.
+func registerTrapImports(builder wazero.HostModuleBuilder) {
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, code uint32) {
+			panic(cellExit{err: &ExitError{Code: code}})
+		}).
+		Export("proc_exit")
+
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod api.Module, reasonPtr, reasonLen, filePtr, fileLen, line uint32) {
+			reason, _ := mod.Memory().Read(reasonPtr, reasonLen)
+			file, _ := mod.Memory().Read(filePtr, fileLen)
+			panic(cellAbort{err: &AbortError{Reason: string(reason), File: string(file), Line: line}})
+		}).
+		Export("abort")
+}
+
+// recoverTrap converts a panic raised by proc_exit/abort back into the
+// typed ExitError/AbortError it carries, and re-panics anything else so
+// genuine host bugs are not swallowed.
+func recoverTrap() error {
+	r := recover()
+	if r == nil {
+		return nil
+	}
+	switch v := r.(type) {
+	case cellExit:
+		return v.err
+	case cellAbort:
+		return v.err
+	default:
+		panic(r)
+	}
+}
+
+// FaultEvent is emitted once per guest trap so firmware can forward it to
+// the SoC telemetry bus.
+type FaultEvent struct {
+	CellName       string
+	Err            error
+	Restarted      bool
+	ConsecutiveN   int
+	BackoffApplied bool
+}
+
+// Supervisor restarts a cell after consecutive traps, backing off
+// exponentially between restarts so a persistently faulty cell cannot spin
+// the host. It does not itself decide whether a fault is fatal; callers
+// report each invocation's outcome via RecordResult.
+type Supervisor struct {
+	maxConsecutive int
+	baseBackoff    time.Duration
+	maxBackoff     time.Duration
+	sleep          func(time.Duration)
+	onFault        func(FaultEvent)
+
+	consecutive   int
+	restartStreak int // number of restarts triggered since the last success
+}
+
+// NewSupervisor returns a Supervisor that restarts the cell after
+// maxConsecutive back-to-back traps, waiting baseBackoff*2^(attempt-1)
+// (capped at maxBackoff) before each restart attempt, and invoking onFault
+// for every trap (whether or not it triggers a restart). A baseBackoff of 0
+// disables the wait entirely, restarting immediately on every attempt.
+func NewSupervisor(maxConsecutive int, baseBackoff, maxBackoff time.Duration, onFault func(FaultEvent)) *Supervisor {
+	return &Supervisor{
+		maxConsecutive: maxConsecutive,
+		baseBackoff:    baseBackoff,
+		maxBackoff:     maxBackoff,
+		sleep:          time.Sleep,
+		onFault:        onFault,
+	}
+}
+
+// RecordResult observes the outcome of one ProcessSensorData call. err is
+// nil on success. Once maxConsecutive traps have been seen in a row,
+// RecordResult blocks for the exponentially growing backoff duration and
+// then invokes restart.
+func (s *Supervisor) RecordResult(cellName string, err error, restart func(attempt int) error) {
+	if err == nil {
+		s.consecutive = 0
+		s.restartStreak = 0
+		return
+	}
+
+	s.consecutive++
+	evt := FaultEvent{CellName: cellName, Err: err, ConsecutiveN: s.consecutive}
+
+	if s.consecutive >= s.maxConsecutive {
+		s.restartStreak++
+		if wait := s.backoffFor(s.restartStreak); wait > 0 {
+			s.sleep(wait)
+			evt.BackoffApplied = true
+		}
+		if restart != nil {
+			evt.Restarted = restart(s.restartStreak) == nil
+		}
+		s.consecutive = 0
+	}
+
+	if s.onFault != nil {
+		s.onFault(evt)
+	}
+}
+
+// backoffFor returns baseBackoff doubled attempt-1 times, capped at
+// maxBackoff (when maxBackoff > 0) so a long-misbehaving cell does not end
+// up waiting unboundedly between restart attempts.
+func (s *Supervisor) backoffFor(attempt int) time.Duration {
+	if s.baseBackoff <= 0 {
+		return 0
+	}
+	wait := s.baseBackoff
+	for i := 1; i < attempt; i++ {
+		if s.maxBackoff > 0 && wait >= s.maxBackoff {
+			return s.maxBackoff
+		}
+		wait *= 2
+	}
+	if s.maxBackoff > 0 && wait > s.maxBackoff {
+		return s.maxBackoff
+	}
+	return wait
+}
+
+// IsTrap reports whether err is any of ExitError, AbortError or ErrTrap —
+// i.e. whether the guest invocation failed because the cell itself faulted,
+// as opposed to a resource limit.
+func IsTrap(err error) bool {
+	var exitErr *ExitError
+	var abortErr *AbortError
+	return errors.As(err, &exitErr) || errors.As(err, &abortErr) || errors.Is(err, ErrTrap)
+}
@@ -0,0 +1,128 @@
+package wasmhost
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExitErrorMessage(t *testing.T) {
+	err := &ExitError{Code: 7}
+	if got, want := err.Error(), "wasmhost: guest cell exited with code 7"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !IsTrap(err) {
+		t.Error("IsTrap(ExitError) = false, want true")
+	}
+}
+
+func TestAbortErrorMessage(t *testing.T) {
+	err := &AbortError{Reason: "index out of range", File: "main.go", Line: 42}
+	want := "wasmhost: guest cell aborted at main.go:42: index out of range"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !IsTrap(err) {
+		t.Error("IsTrap(AbortError) = false, want true")
+	}
+}
+
+func TestIsTrap(t *testing.T) {
+	if !IsTrap(ErrTrap) {
+		t.Error("IsTrap(ErrTrap) = false, want true")
+	}
+	if IsTrap(ErrDeadline) {
+		t.Error("IsTrap(ErrDeadline) = true, want false")
+	}
+	if IsTrap(nil) {
+		t.Error("IsTrap(nil) = true, want false")
+	}
+}
+
+// TestSupervisorRestartsAfterMaxConsecutive checks that a Supervisor leaves
+// the cell alone under maxConsecutive traps and restarts it exactly once
+// every maxConsecutive-th trap.
+func TestSupervisorRestartsAfterMaxConsecutive(t *testing.T) {
+	var faults []FaultEvent
+	s := NewSupervisor(3, 0, 0, func(evt FaultEvent) { faults = append(faults, evt) })
+
+	restarts := 0
+	restart := func(attempt int) error { restarts++; return nil }
+
+	for i := 0; i < 2; i++ {
+		s.RecordResult("cell", ErrTrap, restart)
+	}
+	if restarts != 0 {
+		t.Fatalf("restarts = %d after 2 traps, want 0", restarts)
+	}
+
+	s.RecordResult("cell", ErrTrap, restart)
+	if restarts != 1 {
+		t.Fatalf("restarts = %d after 3rd consecutive trap, want 1", restarts)
+	}
+	if len(faults) != 3 {
+		t.Fatalf("len(faults) = %d, want 3", len(faults))
+	}
+	if !faults[2].Restarted {
+		t.Error("faults[2].Restarted = false, want true")
+	}
+
+	// A success resets the streak, so the next trap alone must not restart.
+	s.RecordResult("cell", nil, restart)
+	s.RecordResult("cell", ErrTrap, restart)
+	if restarts != 1 {
+		t.Fatalf("restarts = %d after streak reset + 1 trap, want 1", restarts)
+	}
+}
+
+// TestSupervisorExponentialBackoff checks that the wait between successive
+// restart attempts doubles each time, capped at maxBackoff.
+func TestSupervisorExponentialBackoff(t *testing.T) {
+	var slept []time.Duration
+	s := NewSupervisor(1, 10*time.Millisecond, 35*time.Millisecond, nil)
+	s.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	restart := func(attempt int) error { return nil }
+	for i := 0; i < 4; i++ {
+		s.RecordResult("cell", ErrTrap, restart)
+	}
+
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 35 * time.Millisecond, 35 * time.Millisecond}
+	if len(slept) != len(want) {
+		t.Fatalf("len(slept) = %d, want %d (%v)", len(slept), len(want), slept)
+	}
+	for i, w := range want {
+		if slept[i] != w {
+			t.Errorf("slept[%d] = %v, want %v", i, slept[i], w)
+		}
+	}
+}
+
+// TestSupervisorZeroBackoffSkipsSleep checks that a zero baseBackoff
+// restarts immediately without reporting BackoffApplied, since the caller
+// explicitly asked for no wait.
+func TestSupervisorZeroBackoffSkipsSleep(t *testing.T) {
+	var faults []FaultEvent
+	s := NewSupervisor(1, 0, 0, func(evt FaultEvent) { faults = append(faults, evt) })
+	s.sleep = func(time.Duration) { t.Fatal("sleep called with zero baseBackoff") }
+
+	s.RecordResult("cell", ErrTrap, func(attempt int) error { return nil })
+	if len(faults) != 1 {
+		t.Fatalf("len(faults) = %d, want 1", len(faults))
+	}
+	if faults[0].BackoffApplied {
+		t.Error("BackoffApplied = true, want false")
+	}
+	if !faults[0].Restarted {
+		t.Error("Restarted = false, want true")
+	}
+}
+
+func TestSupervisorRestartFailureNotRecorded(t *testing.T) {
+	var faults []FaultEvent
+	s := NewSupervisor(1, 0, 0, func(evt FaultEvent) { faults = append(faults, evt) })
+	s.RecordResult("cell", ErrTrap, func(attempt int) error { return errors.New("restart failed") })
+	if faults[0].Restarted {
+		t.Error("Restarted = true, want false on restart failure")
+	}
+}